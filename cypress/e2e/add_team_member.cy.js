@@ -29,11 +29,12 @@ describe("Add team member", () => {
     });
 
     cy.get("#f-search").clear().type("admin");
-    cy.get("button[type=submit]").click();
+    cy.get(".moj-search button[type=submit]").click();
 
     cy.get(".govuk-table__row").should("have.length", 2);
 
     const expected = [
+      "",
       "system admin",
       "system.admin@opgtest.com",
       "Add to team",
@@ -57,4 +58,42 @@ describe("Add team member", () => {
       "You have successfully added system.admin@opgtest.com to the team."
     );
   });
+
+  it("allows me to add several users to a team by email", () => {
+    cy.addMock(
+      "/api/v1/search/users?includeSuspended=1&query=system.admin%40opgtest.com",
+      "GET",
+      {
+        status: 200,
+        body: [
+          {
+            displayName: "system admin",
+            email: "system.admin@opgtest.com",
+            id: 47,
+          },
+        ],
+      }
+    );
+
+    cy.addMock(
+      "/api/v1/search/users?includeSuspended=1&query=nobody%40opgtest.com",
+      "GET",
+      {
+        status: 200,
+        body: [],
+      }
+    );
+
+    cy.addMock("/api/v1/teams/65", "PUT", {
+      status: 200,
+      body: {},
+    });
+
+    cy.contains("summary", "Add users by email address").click();
+    cy.get("#f-emails").type("system.admin@opgtest.com\nnobody@opgtest.com");
+    cy.contains("button", "Add users to team").click();
+
+    cy.contains(".moj-alert", "You have successfully added 1 user to the team.");
+    cy.contains(".govuk-warning-text", "nobody@opgtest.com");
+  });
 });
@@ -10,6 +10,7 @@ describe("Edit user", () => {
     cy.addMock("/api/v1/users/123", "GET", {
       status: 200,
       body: {
+        id: 123,
         firstname: "Hadley",
         surname: "Collins",
         email: "h.collins@opg.example",
@@ -38,8 +39,44 @@ describe("Edit user", () => {
       body: {},
     });
 
-    cy.get("button[type=submit]").click();
+    cy.get(".form button[type=submit]").click();
+
+    cy.get("h2").should("contain", "Check your changes");
+    cy.contains(".govuk-summary-list__row", "Roles added").should(
+      "contain",
+      "System Admin",
+    );
+    cy.get(".govuk-warning-text").should("contain", "System Admin");
+
+    cy.contains("button", "Confirm changes").click();
 
     cy.contains(".moj-alert", "You have successfully edited a user.");
   });
+
+  it("shows roles being removed before saving", () => {
+    cy.get("[name='roles'][value='Finance']").uncheck();
+
+    cy.get(".form button[type=submit]").click();
+
+    cy.contains(".govuk-summary-list__row", "Roles removed").should(
+      "contain",
+      "Finance",
+    );
+    cy.get(".govuk-warning-text").should("not.exist");
+
+    cy.contains("button", "Change").click();
+
+    cy.get("[name='roles'][value='Finance']").should("not.be.checked");
+  });
+
+  it("allows me to resend the activation email", () => {
+    cy.addMock("/api/v1/users/123/resend-confirmation", "POST", {
+      status: 200,
+    });
+
+    cy.contains("button", "Resend activation email").click();
+
+    cy.get("h1").should("contain", "Resend activation email");
+    cy.get(".govuk-body").should("contain", "h.collins@opg.example");
+  });
 });
@@ -0,0 +1,41 @@
+describe("Import users", () => {
+  beforeEach(() => {
+    cy.setupPermissions({ "v1-users": ["post", "put"] });
+
+    cy.addMock("/api/v1/roles", "GET", {
+      status: 200,
+      body: ["System Admin", "Finance"],
+    });
+
+    cy.visit("/users");
+  });
+
+  it("allows me to import users from a CSV file", () => {
+    cy.contains("a", "Import users").click();
+
+    cy.get("#f-file").selectFile({
+      contents: Cypress.Buffer.from(
+        "email,firstname,surname,organisation,roles\n" +
+          "a.able@opg.example,Anne,Able,OPG User,Finance\n" +
+          "b.bell@opg.example,Bob,Bell,COP User,Unknown\n"
+      ),
+      fileName: "users.csv",
+      mimeType: "text/csv",
+    });
+
+    cy.get("button[type=submit]").click();
+
+    cy.get("h1").should("contain", "Check users to import");
+    cy.get(".govuk-table__body tr").should("have.length", 2);
+    cy.get(".govuk-table__body tr:nth-child(2)").should("contain", "Not imported");
+
+    cy.addMock("/api/v1/users", "POST", {
+      status: 201,
+    });
+
+    cy.contains("button", "Import 1 user(s)").click();
+
+    cy.get("h1").should("contain", "Import results");
+    cy.get(".govuk-table__body tr:nth-child(1)").should("contain", "Created");
+  });
+});
@@ -0,0 +1,61 @@
+describe("Move team members", () => {
+  beforeEach(() => {
+    cy.setupPermissions({ "v1-teams": ["put", "post"] });
+
+    cy.addMock("/api/v1/teams/748", "GET", {
+      status: 200,
+      body: {
+        id: 748,
+        displayName: "Finance Team",
+        members: [
+          {
+            id: 12,
+            displayName: "John Ruecker",
+          },
+        ],
+      },
+    });
+
+    cy.addMock("/api/v1/teams/749", "GET", {
+      status: 200,
+      body: {
+        id: 749,
+        displayName: "Allocations Team",
+        members: [],
+      },
+    });
+
+    cy.addMock("/api/v1/teams", "GET", {
+      status: 200,
+      body: [
+        { id: 748, displayName: "Finance Team", members: [] },
+        { id: 749, displayName: "Allocations Team", members: [] },
+      ],
+    });
+
+    cy.visit("/teams/748");
+  });
+
+  it("allows me to move a member to another team", () => {
+    cy.get("label[for=f-select-user-12]").click();
+    cy.contains("button", "Move selected to another team").click();
+
+    cy.url().should("include", "/teams/move-members/748");
+    cy.get(".govuk-list").should("contain", "John Ruecker");
+
+    cy.addMock("/api/v1/teams/748", "PUT", {
+      status: 200,
+      body: {},
+    });
+
+    cy.addMock("/api/v1/teams/749", "PUT", {
+      status: 200,
+      body: {},
+    });
+
+    cy.get("#f-destination").select("Allocations Team");
+    cy.contains("button", "Move users").click();
+
+    cy.url().should("include", "/teams/749");
+  });
+});
@@ -51,24 +51,51 @@ describe("Random Reviews", () => {
     cy.contains("#hook-reviewCycleChange", "Change");
   });
 
-  describe("Edit lay percentage", () => {
-    it("throws an error after inputting the incorrect value", () => {
+  describe("Edit settings", () => {
+    it("checks the values before they are sent", () => {
       cy.get("#hook-layPercentageChange").contains("Change").click();
       cy.get("#f-layPercentage").clear().type("200");
+      cy.get("#f-layPercentage:invalid").should("exist");
 
-      cy.addMock("/supervision-api/v1/random-review-settings", "POST", {
-        status: 400,
-        body: {
-          detail: "Enter a percentage between 0 and 100 for lay cases",
-          status: 400,
-        },
-      });
-
+      cy.get("form").invoke("attr", "novalidate", "novalidate");
+      cy.get("#f-reason").type("Typo");
       cy.get("button[type=submit]").click();
       cy.contains(
-        "#name-error",
-        "Enter a percentage between 0 and 100 for lay cases"
+        ".govuk-error-summary",
+        "Enter a whole number between 0 and 100 for lay cases"
       );
     });
+
+    it("requires a reason for the change", () => {
+      cy.get("#hook-layPercentageChange").contains("Change").click();
+      cy.get("#f-layPercentage").clear().type("25");
+
+      cy.get("button[type=submit]").click();
+      cy.contains(".govuk-error-summary", "Enter a reason for the change");
+    });
+
+    it("previews the number of reviews", () => {
+      cy.get("#hook-layPercentageChange").contains("Change").click();
+      cy.contains("#random-review-impact .hook-impactLay", "200");
+
+      cy.get("#f-layPercentage").clear().type("25");
+      cy.get("#f-reviewCycle").clear().type("2");
+      cy.contains("button", "Preview impact").click();
+
+      cy.contains("#random-review-impact th", "Reviews after change per 2 year cycle");
+      cy.contains("#random-review-impact .hook-impactLay", "250");
+      cy.get("#f-layPercentage").should("have.value", "25");
+    });
+
+    it("proposes the change for approval", () => {
+      cy.get("#hook-layPercentageChange").contains("Change").click();
+      cy.get("#f-layPercentage").clear().type("25");
+      cy.get("#f-reason").type("Audit recommendation");
+
+      cy.contains("button", "Propose changes").click();
+      cy.url().should("include", "/random-reviews");
+      cy.contains("h2", "Changes awaiting approval");
+      cy.contains("#random-review-proposals", "Audit recommendation");
+    });
   });
 });
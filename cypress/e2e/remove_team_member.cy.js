@@ -20,7 +20,7 @@ describe("Team", () => {
 
   it("allows me to remove a member", () => {
     cy.get("label[for=f-select-user-0]").click();
-    cy.get("button[type=submit]").click();
+    cy.contains("button", "Remove selected from team").click();
 
     cy.url().should("include", "/teams/remove-member/748");
     cy.get(".govuk-body").should(
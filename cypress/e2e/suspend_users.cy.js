@@ -0,0 +1,51 @@
+describe("Suspend users", () => {
+  beforeEach(() => {
+    cy.setupPermissions({ "v1-users": ["put"] });
+
+    cy.addMock("/api/v1/search/users?includeSuspended=1&query=anton", "GET", {
+      status: 200,
+      body: [
+        {
+          id: 47,
+          displayName: "Anton Mccoy",
+          email: "anton.mccoy@opgtest.com",
+          teams: [],
+        },
+      ],
+    });
+
+    cy.addMock("/api/v1/users/47", "GET", {
+      status: 200,
+      body: {
+        id: 47,
+        firstname: "Anton",
+        surname: "Mccoy",
+        email: "anton.mccoy@opgtest.com",
+        roles: ["OPG User"],
+      },
+    });
+
+    cy.visit("/users?search=anton");
+  });
+
+  it("allows me to suspend selected users", () => {
+    cy.get("label[for=f-select-user-47]").click();
+    cy.contains("button", "Suspend selected").click();
+
+    cy.url().should("include", "/users/suspend");
+    cy.get(".govuk-body").should(
+      "contain",
+      "Are you sure you want to suspend the following users?"
+    );
+    cy.get(".govuk-list").should("contain", "Anton Mccoy");
+
+    cy.addMock("/api/v1/users/47", "PUT", {
+      status: 200,
+      body: {},
+    });
+
+    cy.contains("button", "Suspend users").click();
+
+    cy.get(".govuk-table__body").should("contain", "Suspended");
+  });
+});
@@ -33,6 +33,16 @@ describe("Teams", () => {
       ],
     });
 
+    cy.addMock("/api/v1/reference-data?filter=teamType", "GET", {
+      status: 200,
+      body: {
+        teamType: [
+          { handle: "ALLOCATIONS", label: "Allocations" },
+          { handle: "FINANCE", label: "Finance" },
+        ],
+      },
+    });
+
     cy.visit("/teams");
   });
 
@@ -40,8 +50,8 @@ describe("Teams", () => {
     cy.get(".govuk-table__row").should("have.length", 3);
 
     const teams = [
-      ["Finance Team", "Supervision — Finance", "1"],
       ["File Creation Team", "LPA", "2"],
+      ["Finance Team", "Supervision — Finance", "1"],
     ];
 
     teams.forEach((team, teamIndex) => {
@@ -53,6 +63,18 @@ describe("Teams", () => {
     });
   });
 
+  it("allows me to sort the teams", () => {
+    cy.get("th[aria-sort=ascending]").should("contain", "Name");
+
+    cy.contains("th a", "Members").click();
+    cy.get("th[aria-sort=ascending]").should("contain", "Members");
+    cy.get(".govuk-table__body > .govuk-table__row:first-child").should("contain", "Finance Team");
+
+    cy.contains("th a", "Members").click();
+    cy.get("th[aria-sort=descending]").should("contain", "Members");
+    cy.get(".govuk-table__body > .govuk-table__row:first-child").should("contain", "File Creation Team");
+  });
+
   it("allows me to search for a team", () => {
     cy.get("#f-search").clear().type("Finance");
     cy.get("button[type=submit]").click();
@@ -65,6 +87,24 @@ describe("Teams", () => {
     cy.get(".govuk-table__body > .govuk-table__row").should("have.length", 0);
   });
 
+  it("allows me to filter the teams", () => {
+    cy.get("#f-service").select("LPA");
+    cy.get("button[type=submit]").click();
+    cy.get(".govuk-table__body > .govuk-table__row").should("have.length", 1);
+    cy.get(".govuk-table__body").should("contain", "File Creation Team");
+
+    cy.get("#f-service").select("All services");
+    cy.get("#f-type").select("Finance");
+    cy.get("button[type=submit]").click();
+    cy.get(".govuk-table__body > .govuk-table__row").should("have.length", 1);
+    cy.get(".govuk-table__body").should("contain", "Finance Team");
+
+    cy.get("#f-type").select("All team types");
+    cy.get("#f-empty").check();
+    cy.get("button[type=submit]").click();
+    cy.get(".govuk-table__body > .govuk-table__row").should("have.length", 0);
+  });
+
   it("allows me to add a new team", () => {
     cy.contains(".govuk-button", "Add new team");
   });
@@ -0,0 +1,77 @@
+describe("User", () => {
+  beforeEach(() => {
+    cy.setupPermissions({ "v1-users": ["put", "delete"] });
+
+    cy.addMock("/api/v1/users/123", "GET", {
+      status: 200,
+      body: {
+        id: 123,
+        firstname: "Hadley",
+        surname: "Collins",
+        email: "h.collins@opg.example",
+        roles: ["OPG User", "Finance", "Self-Allocation"],
+        suspended: false,
+      },
+    });
+
+    cy.addMock("/api/v1/teams", "GET", {
+      status: 200,
+      body: [
+        {
+          id: 14,
+          displayName: "Finance Team",
+          members: [{ id: 123, displayName: "Hadley Collins" }],
+        },
+        {
+          id: 15,
+          displayName: "Cover Team",
+          members: [{ id: 123, displayName: "Hadley Collins" }],
+          teamType: { handle: "ALLOCATIONS", label: "Allocations" },
+        },
+        {
+          id: 16,
+          displayName: "Other Team",
+          members: [{ id: 7, displayName: "Someone Else" }],
+        },
+      ],
+    });
+
+    cy.visit("/users/123");
+  });
+
+  it("shows the user's details", () => {
+    cy.contains("h1", "Hadley Collins");
+    cy.contains(".govuk-summary-list__row", "Email").should(
+      "contain",
+      "h.collins@opg.example",
+    );
+    cy.contains(".govuk-summary-list__row", "Status").should(
+      "contain",
+      "Active",
+    );
+    cy.contains(".govuk-summary-list__row", "Organisation").should(
+      "contain",
+      "OPG User",
+    );
+    cy.contains(".govuk-summary-list__row", "Roles").should(
+      "contain",
+      "Finance, Self-Allocation",
+    );
+  });
+
+  it("shows all of the user's teams", () => {
+    cy.get(".govuk-table__body > .govuk-table__row").should("have.length", 2);
+    cy.get(".govuk-table__body").should("contain", "Finance Team");
+    cy.get(".govuk-table__body").should("contain", "Cover Team");
+    cy.get(".govuk-table__body").should("not.contain", "Other Team");
+  });
+
+  it("links to edit and delete the user", () => {
+    cy.contains(".govuk-button", "Edit user")
+      .should("have.attr", "href")
+      .and("contain", "/edit-user/123");
+    cy.contains(".govuk-button", "Delete user")
+      .should("have.attr", "href")
+      .and("contain", "/delete-user/123");
+  });
+});
@@ -18,6 +18,7 @@ describe("Users", () => {
     });
 
     const expected = [
+      "",
       "system admin",
       "",
       "system.admin@opgtest.com",
@@ -45,6 +46,7 @@ describe("Users", () => {
     });
 
     const expected = [
+      "",
       "Anton Mccoy",
       "Visits Team",
       "anton.mccoy@opgtest.com",
@@ -54,11 +56,58 @@ describe("Users", () => {
     search("anton", expected);
   });
 
+  it("shows all of a user's teams and filters by team", () => {
+    cy.addMock("/api/v1/search/users?includeSuspended=1&query=cover", "GET", {
+      status: 200,
+      body: [
+        {
+          id: 1,
+          displayName: "Anton Mccoy",
+          email: "anton.mccoy@opgtest.com",
+          teams: [
+            { id: 10, displayName: "Visits Team" },
+            { id: 11, displayName: "Cover Team" },
+          ],
+        },
+        {
+          id: 2,
+          displayName: "Milo Nihei",
+          email: "milo.nihei@opgtest.com",
+          teams: [{ id: 10, displayName: "Visits Team" }],
+        },
+      ],
+    });
+
+    cy.get("#f-search").clear().type("cover");
+    cy.get(".moj-search button[type=submit]").click();
+
+    cy.get(".govuk-table__body > .govuk-table__row").should("have.length", 2);
+    cy.get(".govuk-table__body > .govuk-table__row")
+      .first()
+      .should("contain", "Visits Team, Cover Team");
+
+    cy.get("#f-team").select("Cover Team");
+    cy.get(".moj-search button[type=submit]").click();
+
+    cy.get(".govuk-table__body > .govuk-table__row").should("have.length", 1);
+    cy.get(".govuk-table__body").should("contain", "Anton Mccoy");
+
+    cy.get("#f-team").select("All teams");
+    cy.get(".moj-search button[type=submit]").click();
+
+    cy.contains("th a", "Email").click();
+    cy.contains("th a", "Email").click();
+    cy.get("th[aria-sort=descending]").should("contain", "Email");
+    cy.get(".govuk-table__body > .govuk-table__row")
+      .first()
+      .should("contain", "Milo Nihei");
+  });
+
   function search(searchTerm, expected) {
     cy.get(".govuk-table").should("not.exist");
 
     cy.get("#f-search").clear().type(searchTerm);
-    cy.get("button[type=submit]").click();
+    cy.get(".moj-search button[type=submit]").click();
 
     cy.get(".govuk-table__row").should("have.length", 2);
 
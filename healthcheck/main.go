@@ -8,11 +8,33 @@ import (
 	"regexp"
 )
 
+// Usage: healthcheck [live|ready]
+//
+// "live" (the default) checks the path in HEALTHCHECK. "ready" checks the path
+// in HEALTHCHECK_READY, or HEALTHCHECK with "/ready" appended if it is unset.
 func main() {
 
 	port := os.Getenv("PORT")
 	healthcheck := os.Getenv("HEALTHCHECK")
 
+	mode := "live"
+	if len(os.Args) > 1 {
+		mode = os.Args[1]
+	}
+
+	switch mode {
+	case "live":
+	case "ready":
+		if ready := os.Getenv("HEALTHCHECK_READY"); ready != "" {
+			healthcheck = ready
+		} else {
+			healthcheck += "/ready"
+		}
+	default:
+		fmt.Println("Invalid check, must be live or ready")
+		os.Exit(1)
+	}
+
 	// Validate port is numeric
 	if _, err := fmt.Sscanf(port, "%d", new(int)); err != nil {
 		fmt.Println("Invalid PORT environment variable")
@@ -35,7 +57,7 @@ func main() {
 	res, err := http.Get(u.String())
 	fmt.Println("Checking ", u.String())
 	if err != nil {
-		fmt.Println("Healthcheck failed, status: ", res.StatusCode)
+		fmt.Println("Healthcheck failed, error: ", err)
 		os.Exit(1)
 	}
 	if res.StatusCode != 200 {
@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+type Outcome string
+
+const (
+	OutcomeSuccess  Outcome = "success"
+	OutcomeRejected Outcome = "rejected"
+	OutcomeError    Outcome = "error"
+)
+
+type Actor struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type Event struct {
+	Time             time.Time                    `json:"time"`
+	Action           string                       `json:"action"`
+	Actor            Actor                        `json:"actor"`
+	Target           string                       `json:"target"`
+	Before           interface{}                  `json:"before,omitempty"`
+	After            interface{}                  `json:"after,omitempty"`
+	Outcome          Outcome                      `json:"outcome"`
+	Error            string                       `json:"error,omitempty"`
+	ValidationErrors map[string]map[string]string `json:"validationErrors,omitempty"`
+}
+
+type Sink interface {
+	Record(context.Context, Event) error
+}
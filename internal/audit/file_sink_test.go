@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSink(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	sink, err := NewFileSink(path)
+	assert.Nil(err)
+
+	events := []Event{
+		{
+			Time:    time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+			Action:  "edit-user",
+			Actor:   Actor{ID: 1, Name: "Anne Able", Email: "a@example.com"},
+			Target:  "user:123",
+			Before:  map[string]interface{}{"roles": []interface{}{"Finance"}},
+			After:   map[string]interface{}{"roles": []interface{}{"Finance", "System Admin"}},
+			Outcome: OutcomeSuccess,
+		},
+		{
+			Time:             time.Date(2021, 1, 2, 3, 4, 6, 0, time.UTC),
+			Action:           "delete-team",
+			Target:           "team:5",
+			Outcome:          OutcomeRejected,
+			ValidationErrors: map[string]map[string]string{"#": {"error": "Team has members"}},
+		},
+	}
+
+	for _, event := range events {
+		assert.Nil(sink.Record(context.Background(), event))
+	}
+	assert.Nil(sink.Close())
+
+	data, err := os.ReadFile(path)
+	assert.Nil(err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(lines, 2)
+
+	for i, line := range lines {
+		var got Event
+		assert.Nil(json.Unmarshal([]byte(line), &got))
+		assert.Equal(events[i], got)
+	}
+}
+
+func TestFileSinkAppends(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	assert.Nil(os.WriteFile(path, []byte("{}\n"), 0600))
+
+	sink, err := NewFileSink(path)
+	assert.Nil(err)
+	assert.Nil(sink.Record(context.Background(), Event{Action: "add-user"}))
+	assert.Nil(sink.Close())
+
+	data, _ := os.ReadFile(path)
+	assert.Equal(2, strings.Count(string(data), "\n"))
+}
+
+func TestNewFileSinkError(t *testing.T) {
+	_, err := NewFileSink(filepath.Join(t.TempDir(), "missing", "audit.jsonl"))
+	assert.NotNil(t, err)
+}
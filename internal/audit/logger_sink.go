@@ -0,0 +1,20 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LoggerSink writes each event to a structured logger.
+type LoggerSink struct {
+	logger *slog.Logger
+}
+
+func NewLoggerSink(logger *slog.Logger) *LoggerSink {
+	return &LoggerSink{logger: logger}
+}
+
+func (s *LoggerSink) Record(ctx context.Context, event Event) error {
+	s.logger.InfoContext(ctx, "audit event", slog.Any("audit", event))
+	return nil
+}
@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerSink(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	sink := NewLoggerSink(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	err := sink.Record(context.Background(), Event{
+		Action:  "delete-user",
+		Actor:   Actor{ID: 1, Email: "a@example.com"},
+		Target:  "user:123",
+		Outcome: OutcomeSuccess,
+	})
+	assert.Nil(err)
+
+	var line struct {
+		Msg   string `json:"msg"`
+		Audit Event  `json:"audit"`
+	}
+	assert.Nil(json.Unmarshal(buf.Bytes(), &line))
+
+	assert.Equal("audit event", line.Msg)
+	assert.Equal("delete-user", line.Audit.Action)
+	assert.Equal(1, line.Audit.Actor.ID)
+	assert.Equal("user:123", line.Audit.Target)
+	assert.Equal(OutcomeSuccess, line.Audit.Outcome)
+}
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+// HTTPClient records the count and duration of each call made to Sirius,
+// labelled by the sirius.Client method that made it.
+type HTTPClient struct {
+	http    sirius.HTTPClient
+	metrics *Metrics
+	now     func() time.Time
+}
+
+func NewHTTPClient(httpClient sirius.HTTPClient, metrics *Metrics) *HTTPClient {
+	return &HTTPClient{
+		http:    httpClient,
+		metrics: metrics,
+		now:     time.Now,
+	}
+}
+
+func (c *HTTPClient) Do(req *http.Request) (*http.Response, error) {
+	start := c.now()
+
+	resp, err := c.http.Do(req)
+
+	code := 0
+	if err == nil {
+		code = resp.StatusCode
+	}
+
+	c.metrics.ObserveSiriusRequest(sirius.Operation(req), code, c.now().Sub(start))
+
+	return resp, err
+}
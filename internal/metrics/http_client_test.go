@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockHTTPClient struct {
+	resp *http.Response
+	err  error
+}
+
+func (m *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return m.resp, m.err
+}
+
+func TestHTTPClient(t *testing.T) {
+	m := New()
+	client := NewHTTPClient(&mockHTTPClient{resp: &http.Response{StatusCode: http.StatusNotFound}}, m)
+
+	now := time.Now()
+	client.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost/api/v1/teams", nil)
+
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.siriusRequests.WithLabelValues("", "404")))
+}
+
+func TestHTTPClientError(t *testing.T) {
+	m := New()
+	expectedErr := errors.New("oops")
+	client := NewHTTPClient(&mockHTTPClient{err: expectedErr}, m)
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost/api/v1/teams", nil)
+
+	_, err := client.Do(req)
+	assert.Equal(t, expectedErr, err)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.siriusRequests.WithLabelValues("", "error")))
+}
+
+func TestHTTPClientInsideResilientClient(t *testing.T) {
+	m := New()
+	unavailable := &mockHTTPClient{resp: &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(""))}}
+	client := sirius.NewResilientHTTPClient(NewHTTPClient(unavailable, m), sirius.RetryPolicy{MaxAttempts: 3}, sirius.BreakerPolicy{FailureThreshold: 3, Cooldown: time.Hour})
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost/api/v1/teams", nil)
+
+	_, err := client.Do(req)
+	assert.ErrorIs(t, err, sirius.ErrUnavailable)
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.siriusRequests.WithLabelValues("", "503")))
+
+	_, err = client.Do(req)
+	assert.Equal(t, sirius.ErrUnavailable, err)
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.siriusRequests.WithLabelValues("", "503")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.siriusRequests.WithLabelValues("", "error")))
+}
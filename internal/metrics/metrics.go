@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "user_management"
+
+// Metrics holds the collectors exposed on /metrics. A nil *Metrics can be
+// used, in which case nothing is recorded.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+
+	siriusRequests        *prometheus.CounterVec
+	siriusRequestDuration *prometheus.HistogramVec
+
+	validationFailures *prometheus.CounterVec
+	permissionDenials  *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Requests handled, by route and status code.",
+		}, []string{"route", "code"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to handle requests, by route and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "code"}),
+		siriusRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sirius_requests_total",
+			Help:      "Calls made to Sirius, including retries, by client method and status code.",
+		}, []string{"operation", "code"}),
+		siriusRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "sirius_request_duration_seconds",
+			Help:      "Time taken by calls to Sirius, by client method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "code"}),
+		validationFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "validation_failures_total",
+			Help:      "Requests rejected because the submitted data was invalid, by route.",
+		}, []string{"route"}),
+		permissionDenials: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "permission_denials_total",
+			Help:      "Requests refused because the user did not have permission, by route.",
+		}, []string{"route"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.siriusRequests,
+		m.siriusRequestDuration,
+		m.validationFailures,
+		m.permissionDenials,
+	)
+
+	return m
+}
+
+// Handler serves the collected metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) ObserveRequest(route string, code int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	status := strconv.Itoa(code)
+	m.requests.WithLabelValues(route, status).Inc()
+	m.requestDuration.WithLabelValues(route, status).Observe(duration.Seconds())
+}
+
+// ObserveSiriusRequest records a call to Sirius. A code of 0 means that no
+// response was received.
+func (m *Metrics) ObserveSiriusRequest(operation string, code int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	status := "error"
+	if code != 0 {
+		status = strconv.Itoa(code)
+	}
+
+	m.siriusRequests.WithLabelValues(operation, status).Inc()
+	m.siriusRequestDuration.WithLabelValues(operation, status).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ValidationFailed(route string) {
+	if m == nil {
+		return
+	}
+
+	m.validationFailures.WithLabelValues(route).Inc()
+}
+
+func (m *Metrics) PermissionDenied(route string) {
+	if m == nil {
+		return
+	}
+
+	m.permissionDenials.WithLabelValues(route).Inc()
+}
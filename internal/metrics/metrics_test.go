@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObserveRequest(t *testing.T) {
+	m := New()
+
+	m.ObserveRequest("/teams", http.StatusOK, time.Second)
+	m.ObserveRequest("/teams", http.StatusOK, time.Second)
+	m.ObserveRequest("/teams", http.StatusInternalServerError, time.Second)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.requests.WithLabelValues("/teams", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.requests.WithLabelValues("/teams", "500")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.requestDuration))
+}
+
+func TestObserveSiriusRequest(t *testing.T) {
+	m := New()
+
+	m.ObserveSiriusRequest("Teams", http.StatusOK, time.Second)
+	m.ObserveSiriusRequest("Teams", 0, time.Second)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.siriusRequests.WithLabelValues("Teams", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.siriusRequests.WithLabelValues("Teams", "error")))
+}
+
+func TestValidationFailedAndPermissionDenied(t *testing.T) {
+	m := New()
+
+	m.ValidationFailed("/teams/add")
+	m.PermissionDenied("/teams/add")
+	m.PermissionDenied("/teams/add")
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.validationFailures.WithLabelValues("/teams/add")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.permissionDenials.WithLabelValues("/teams/add")))
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+
+	assert.NotPanics(t, func() {
+		m.ObserveRequest("/teams", http.StatusOK, time.Second)
+		m.ObserveSiriusRequest("Teams", http.StatusOK, time.Second)
+		m.ValidationFailed("/teams/add")
+		m.PermissionDenied("/teams/add")
+	})
+}
+
+func TestHandler(t *testing.T) {
+	m := New()
+	m.ObserveRequest("/teams", http.StatusOK, time.Second)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+
+	m.Handler().ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.Contains(w.Body.String(), `user_management_http_requests_total{code="200",route="/teams"} 1`))
+}
@@ -0,0 +1,41 @@
+package randomreview
+
+import (
+	"strconv"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+// FillEdit completes an edit by keeping the current value of any setting it
+// leaves blank.
+func FillEdit(current sirius.RandomReviews, edit sirius.EditRandomReview) sirius.EditRandomReview {
+	if edit.LayPercentage == "" {
+		edit.LayPercentage = strconv.Itoa(current.LayPercentage)
+	}
+
+	if edit.PaPercentage == "" {
+		edit.PaPercentage = strconv.Itoa(current.PaPercentage)
+	}
+
+	if edit.ProPercentage == "" {
+		edit.ProPercentage = strconv.Itoa(current.ProPercentage)
+	}
+
+	if edit.ReviewCycle == "" {
+		edit.ReviewCycle = strconv.Itoa(current.ReviewCycle)
+	}
+
+	return edit
+}
+
+// EditedSettings gives the settings that result from a complete edit that
+// Sirius has accepted.
+func EditedSettings(edit sirius.EditRandomReview) sirius.RandomReviews {
+	var settings sirius.RandomReviews
+	settings.LayPercentage, _ = strconv.Atoi(edit.LayPercentage)
+	settings.PaPercentage, _ = strconv.Atoi(edit.PaPercentage)
+	settings.ProPercentage, _ = strconv.Atoi(edit.ProPercentage)
+	settings.ReviewCycle, _ = strconv.Atoi(edit.ReviewCycle)
+
+	return settings
+}
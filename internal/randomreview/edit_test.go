@@ -0,0 +1,14 @@
+package randomreview
+
+import (
+	"testing"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFillEdit(t *testing.T) {
+	assert.Equal(t,
+		sirius.EditRandomReview{LayPercentage: "1", PaPercentage: "5", ProPercentage: "3", ReviewCycle: "4"},
+		FillEdit(sirius.RandomReviews{LayPercentage: 1, PaPercentage: 2, ProPercentage: 3, ReviewCycle: 4}, sirius.EditRandomReview{PaPercentage: "5"}))
+}
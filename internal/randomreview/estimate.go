@@ -0,0 +1,44 @@
+package randomreview
+
+import "github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+
+// Population is the number of deputies of each type that random reviews are
+// chosen from.
+type Population struct {
+	Lay int
+	PA  int
+	Pro int
+}
+
+// Known reports whether any deputy counts have been given.
+func (p Population) Known() bool {
+	return p != Population{}
+}
+
+// Estimate is the expected number of deputies of each type that will be
+// reviewed in a review cycle of ReviewCycle years.
+type Estimate struct {
+	Lay         int
+	PA          int
+	Pro         int
+	Total       int
+	ReviewCycle int
+}
+
+// EstimateReviews applies the random review percentages to the population,
+// rounding each type to the nearest whole review.
+func EstimateReviews(population Population, settings sirius.RandomReviews) Estimate {
+	estimate := Estimate{
+		Lay:         percentageOf(population.Lay, settings.LayPercentage),
+		PA:          percentageOf(population.PA, settings.PaPercentage),
+		Pro:         percentageOf(population.Pro, settings.ProPercentage),
+		ReviewCycle: settings.ReviewCycle,
+	}
+	estimate.Total = estimate.Lay + estimate.PA + estimate.Pro
+
+	return estimate
+}
+
+func percentageOf(n, percentage int) int {
+	return (n*percentage + 50) / 100
+}
@@ -0,0 +1,21 @@
+package randomreview
+
+import (
+	"testing"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateReviews(t *testing.T) {
+	population := Population{Lay: 12345, PA: 250, Pro: 3}
+	settings := sirius.RandomReviews{LayPercentage: 10, PaPercentage: 2, ProPercentage: 50, ReviewCycle: 2}
+
+	assert.Equal(t, Estimate{Lay: 1235, PA: 5, Pro: 2, Total: 1242, ReviewCycle: 2}, EstimateReviews(population, settings))
+	assert.Equal(t, Estimate{}, EstimateReviews(population, sirius.RandomReviews{}))
+}
+
+func TestPopulationKnown(t *testing.T) {
+	assert.False(t, Population{}.Known())
+	assert.True(t, Population{Pro: 1}.Known())
+}
@@ -0,0 +1,180 @@
+package randomreview
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+type Client interface {
+	RandomReviews(sirius.Context) (sirius.RandomReviews, error)
+	EditRandomReviewSettings(sirius.Context, sirius.EditRandomReview) error
+}
+
+// Scheduler makes scheduled changes that have been approved once they are
+// due. As it does not act on behalf of a request, auth provides the Sirius
+// session to use.
+type Scheduler struct {
+	logger   *slog.Logger
+	client   Client
+	store    *Store
+	auth     func(context.Context) sirius.Context
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewScheduler(logger *slog.Logger, client Client, store *Store, auth func(context.Context) sirius.Context, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		logger:   logger,
+		client:   client,
+		store:    store,
+		auth:     auth,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Run applies due changes every interval until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.ApplyDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ApplyDue makes each approved proposal that has reached its effective time,
+// in the order they take effect, recording whether it succeeded. If Sirius
+// cannot be reached the remaining proposals are left for the next run, so
+// that they are not made out of order.
+func (s *Scheduler) ApplyDue(ctx context.Context) {
+	proposals, err := s.store.Proposals(ctx)
+	if err != nil {
+		s.logger.Error("could not list random review proposals", slog.Any("err", err.Error()))
+		return
+	}
+
+	now := s.now()
+
+	var due []Proposal
+	for _, proposal := range proposals {
+		if proposal.Status == StatusApproved && proposal.Due(now) {
+			due = append(due, proposal)
+		}
+	}
+
+	slices.SortFunc(due, func(a, b Proposal) int {
+		return cmp.Or(a.EffectiveAt.Compare(b.EffectiveAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	for _, proposal := range due {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !s.apply(ctx, proposal) {
+			return
+		}
+	}
+}
+
+// apply makes a proposal, returning false if it should be tried again later.
+func (s *Scheduler) apply(ctx context.Context, proposal Proposal) bool {
+	logger := s.logger.With(slog.Int("id", proposal.ID))
+
+	// the change is made with a session that belongs to no one, so check
+	// again that two people agreed to it
+	if proposal.DecidedBy.ID == 0 || proposal.DecidedBy.ID == proposal.ProposedBy.ID {
+		logger.Error("scheduled random review change was not approved by someone other than its proposer")
+		return true
+	}
+
+	// claim the proposal so that it cannot be cancelled while the change is
+	// being made, skipping it if it was cancelled after being listed
+	if err := s.store.ClaimProposal(ctx, proposal.ID, StatusApproved); errors.Is(err, ErrNotPending) {
+		return true
+	} else if err != nil {
+		logger.Error("could not claim scheduled random review change", slog.Any("err", err.Error()))
+		return true
+	}
+
+	siriusCtx := s.auth(ctx)
+
+	before, err := s.client.RandomReviews(siriusCtx)
+
+	var edit sirius.EditRandomReview
+	if err == nil {
+		edit = FillEdit(before, proposal.Settings)
+		err = s.client.EditRandomReviewSettings(siriusCtx, edit)
+	}
+
+	if verr, ok := err.(sirius.ValidationError); ok {
+		err = fmt.Errorf("rejected by Sirius: %s", validationMessage(verr))
+	} else if err != nil {
+		logger.Error("could not make scheduled random review change", slog.Any("err", err.Error()))
+
+		if err := s.store.ReleaseProposal(ctx, proposal.ID, StatusApproved); err != nil {
+			logger.Error("could not release scheduled random review change", slog.Any("err", err.Error()))
+		}
+
+		return false
+	}
+
+	now := s.now().UTC()
+
+	if err := s.store.CompleteProposal(ctx, proposal.ID, now, err); err != nil {
+		logger.Error("could not record outcome of scheduled random review change", slog.Any("err", err.Error()))
+	}
+
+	if err != nil {
+		logger.Error("scheduled random review change failed", slog.Any("err", err.Error()))
+		return true
+	}
+
+	logger.Info("scheduled random review change applied")
+
+	if err := s.store.RecordChange(ctx, Change{
+		Time:       now,
+		By:         proposal.ProposedBy,
+		ApprovedBy: proposal.DecidedBy,
+		Before:     before,
+		After:      EditedSettings(edit),
+		Reason:     proposal.Reason,
+	}); err != nil {
+		logger.Error("could not record random review change", slog.Any("err", err.Error()))
+	}
+
+	return true
+}
+
+// validationMessage describes why Sirius rejected a change, as there is no
+// form to show its errors against.
+func validationMessage(verr sirius.ValidationError) string {
+	var messages []string
+	if verr.Message != "" {
+		messages = append(messages, verr.Message)
+	}
+
+	for _, field := range slices.Sorted(maps.Keys(verr.Errors)) {
+		for _, message := range slices.Sorted(maps.Values(verr.Errors[field])) {
+			messages = append(messages, field+": "+message)
+		}
+	}
+
+	return strings.Join(messages, "; ")
+}
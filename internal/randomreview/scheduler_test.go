@@ -0,0 +1,189 @@
+package randomreview
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClient struct {
+	current sirius.RandomReviews
+	edits   []sirius.EditRandomReview
+	lastCtx sirius.Context
+	err     error
+}
+
+func (m *mockClient) RandomReviews(ctx sirius.Context) (sirius.RandomReviews, error) {
+	m.lastCtx = ctx
+	return m.current, nil
+}
+
+func (m *mockClient) EditRandomReviewSettings(ctx sirius.Context, edit sirius.EditRandomReview) error {
+	m.lastCtx = ctx
+	if m.err != nil {
+		return m.err
+	}
+
+	m.edits = append(m.edits, edit)
+	m.current = EditedSettings(edit)
+	return nil
+}
+
+func newTestScheduler(client Client, store *Store, now time.Time) *Scheduler {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	auth := func(ctx context.Context) sirius.Context {
+		return sirius.Context{Context: ctx, XSRFToken: "abc"}
+	}
+
+	s := NewScheduler(logger, client, store, auth, time.Minute)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+// propose adds a proposal to the store, approving it if approvedBy is given.
+func propose(t *testing.T, store *Store, proposal Proposal, approvedBy User) Proposal {
+	ctx := context.Background()
+
+	proposal, err := store.Propose(ctx, proposal)
+	assert.Nil(t, err)
+
+	if approvedBy != (User{}) {
+		assert.Nil(t, store.DecideProposal(ctx, proposal.ID, StatusApproved, approvedBy, proposal.CreatedAt, ""))
+	}
+
+	proposal, _ = store.Proposal(ctx, proposal.ID)
+	return proposal
+}
+
+func TestSchedulerApplyDue(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	now := time.Date(2021, 6, 1, 9, 0, 0, 0, time.UTC)
+	store, _ := NewStore("")
+
+	anne := User{ID: 1, Name: "Anne Able"}
+	bob := User{ID: 2, Name: "Bob Baker"}
+
+	second := propose(t, store, Proposal{
+		ProposedBy:  anne,
+		EffectiveAt: now,
+		Settings:    sirius.EditRandomReview{ReviewCycle: "2"},
+	}, bob)
+	first := propose(t, store, Proposal{
+		ProposedBy:  anne,
+		EffectiveAt: now.Add(-time.Hour),
+		Settings:    sirius.EditRandomReview{LayPercentage: "15"},
+		Reason:      "first",
+	}, bob)
+	notApproved := propose(t, store, Proposal{ProposedBy: anne, EffectiveAt: now.Add(-time.Hour)}, User{})
+	future := propose(t, store, Proposal{ProposedBy: anne, EffectiveAt: now.Add(time.Minute)}, bob)
+	ownApproval := propose(t, store, Proposal{ProposedBy: anne, EffectiveAt: now.Add(-time.Hour)}, anne)
+
+	client := &mockClient{current: sirius.RandomReviews{LayPercentage: 10, PaPercentage: 20, ProPercentage: 30, ReviewCycle: 1}}
+
+	newTestScheduler(client, store, now).ApplyDue(ctx)
+
+	assert.Equal("abc", client.lastCtx.XSRFToken)
+	assert.Equal([]sirius.EditRandomReview{
+		{LayPercentage: "15", PaPercentage: "20", ProPercentage: "30", ReviewCycle: "1"},
+		{LayPercentage: "15", PaPercentage: "20", ProPercentage: "30", ReviewCycle: "2"},
+	}, client.edits)
+
+	for _, proposal := range []Proposal{first, second} {
+		applied, _ := store.Proposal(ctx, proposal.ID)
+		assert.Equal(StatusApplied, applied.Status)
+		assert.Equal(bob, applied.DecidedBy)
+		assert.True(now.Equal(applied.AppliedAt))
+	}
+
+	for _, proposal := range []Proposal{notApproved, future, ownApproval} {
+		unchanged, _ := store.Proposal(ctx, proposal.ID)
+		assert.Equal(proposal, unchanged)
+	}
+
+	changes, _ := store.Changes(ctx)
+	assert.Len(changes, 2)
+	assert.Equal(anne, changes[1].By)
+	assert.Equal(bob, changes[1].ApprovedBy)
+	assert.Equal("first", changes[1].Reason)
+	assert.Equal(10, changes[1].Before.LayPercentage)
+	assert.Equal(15, changes[1].After.LayPercentage)
+	assert.Equal(2, changes[0].After.ReviewCycle)
+}
+
+func TestSchedulerApplyDueRejected(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	now := time.Date(2021, 6, 1, 9, 0, 0, 0, time.UTC)
+	store, _ := NewStore("")
+
+	proposal := propose(t, store, Proposal{ProposedBy: User{ID: 1}, EffectiveAt: now, Settings: sirius.EditRandomReview{LayPercentage: "150"}}, User{ID: 2})
+
+	client := &mockClient{err: sirius.ValidationError{
+		Errors: sirius.ValidationErrors{"layPercentage": {"lessThan": "Must be 100 or less"}},
+	}}
+
+	newTestScheduler(client, store, now).ApplyDue(ctx)
+
+	failed, _ := store.Proposal(ctx, proposal.ID)
+	assert.Equal(StatusFailed, failed.Status)
+	assert.Equal("rejected by Sirius: layPercentage: Must be 100 or less", failed.Error)
+
+	changes, _ := store.Changes(ctx)
+	assert.Empty(changes)
+}
+
+func TestSchedulerApplyDueUnavailable(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	now := time.Date(2021, 6, 1, 9, 0, 0, 0, time.UTC)
+	store, _ := NewStore("")
+
+	first := propose(t, store, Proposal{ProposedBy: User{ID: 1}, EffectiveAt: now.Add(-time.Hour), Settings: sirius.EditRandomReview{LayPercentage: "15"}}, User{ID: 2})
+	second := propose(t, store, Proposal{ProposedBy: User{ID: 1}, EffectiveAt: now, Settings: sirius.EditRandomReview{LayPercentage: "25"}}, User{ID: 2})
+
+	client := &mockClient{err: errors.New("unavailable")}
+
+	newTestScheduler(client, store, now).ApplyDue(ctx)
+
+	for _, proposal := range []Proposal{first, second} {
+		unchanged, _ := store.Proposal(ctx, proposal.ID)
+		assert.Equal(StatusApproved, unchanged.Status)
+	}
+
+	client.err = nil
+	newTestScheduler(client, store, now).ApplyDue(ctx)
+
+	assert.Equal([]sirius.EditRandomReview{
+		{LayPercentage: "15", PaPercentage: "0", ProPercentage: "0", ReviewCycle: "0"},
+		{LayPercentage: "25", PaPercentage: "0", ProPercentage: "0", ReviewCycle: "0"},
+	}, client.edits)
+}
+
+func TestSchedulerRunStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	store, _ := NewStore("")
+
+	done := make(chan struct{})
+	go func() {
+		newTestScheduler(&mockClient{}, store, time.Now()).Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+}
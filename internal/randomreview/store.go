@@ -0,0 +1,296 @@
+package randomreview
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+type User struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// Change is a record of the random review settings being updated.
+type Change struct {
+	ID         int                  `json:"id"`
+	Time       time.Time            `json:"time"`
+	By         User                 `json:"by"`
+	ApprovedBy User                 `json:"approvedBy,omitzero"`
+	Before     sirius.RandomReviews `json:"before"`
+	After      sirius.RandomReviews `json:"after"`
+	Reason     string               `json:"reason,omitempty"`
+}
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusApproved  Status = "approved"
+	StatusApproving Status = "approving"
+	StatusApplied   Status = "applied"
+	StatusRejected  Status = "rejected"
+	StatusCancelled Status = "cancelled"
+	StatusFailed    Status = "failed"
+)
+
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrNotPending = errors.New("no longer pending")
+)
+
+// Proposal is an edit to the random review settings that must be approved
+// by someone other than the person who proposed it before it is made. Blank
+// values in Settings are left as they are when the change is approved.
+//
+// A proposal with an EffectiveAt is a scheduled change. If it is approved
+// before that time it becomes approved, rather than applied, and the Scheduler
+// makes the change once it is due.
+type Proposal struct {
+	ID          int                     `json:"id"`
+	CreatedAt   time.Time               `json:"createdAt"`
+	ProposedBy  User                    `json:"proposedBy"`
+	Before      sirius.RandomReviews    `json:"before"`
+	Settings    sirius.EditRandomReview `json:"settings"`
+	Reason      string                  `json:"reason"`
+	EffectiveAt time.Time               `json:"effectiveAt,omitzero"`
+	Status      Status                  `json:"status"`
+	DecidedAt   time.Time               `json:"decidedAt,omitzero"`
+	DecidedBy   User                    `json:"decidedBy,omitzero"`
+	Comment     string                  `json:"comment,omitempty"`
+	AppliedAt   time.Time               `json:"appliedAt,omitzero"`
+	Error       string                  `json:"error,omitempty"`
+}
+
+// Due reports whether the proposal can be made at now.
+func (p Proposal) Due(now time.Time) bool {
+	return !p.EffectiveAt.After(now)
+}
+
+type state struct {
+	LastID    int        `json:"lastId"`
+	Changes   []Change   `json:"changes"`
+	Proposals []Proposal `json:"proposals"`
+}
+
+// Store keeps the state of random review settings that Sirius does not. If it
+// is given a path the state is saved to that file after each update, and read
+// from it when the store is created.
+type Store struct {
+	mu    sync.Mutex
+	path  string
+	state state
+}
+
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &s.state); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// RecordChange adds a change to the history, giving it the next ID.
+func (s *Store) RecordChange(ctx context.Context, change Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.update(func(st *state) {
+		st.LastID++
+		change.ID = st.LastID
+		st.Changes = append(st.Changes, change)
+	})
+}
+
+// Changes lists the recorded changes, most recent first.
+func (s *Store) Changes(ctx context.Context) ([]Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changes := slices.Clone(s.state.Changes)
+	slices.Reverse(changes)
+
+	return changes, nil
+}
+
+// Propose adds a pending proposal, returning it with its ID.
+func (s *Store) Propose(ctx context.Context, proposal Proposal) (Proposal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.update(func(st *state) {
+		st.LastID++
+		proposal.ID = st.LastID
+		proposal.Status = StatusPending
+		st.Proposals = append(st.Proposals, proposal)
+	})
+
+	return proposal, err
+}
+
+// Proposals lists all proposals, most recent first.
+func (s *Store) Proposals(ctx context.Context) ([]Proposal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proposals := slices.Clone(s.state.Proposals)
+	slices.Reverse(proposals)
+
+	return proposals, nil
+}
+
+func (s *Store) Proposal(ctx context.Context, id int) (Proposal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, proposal := range s.state.Proposals {
+		if proposal.ID == id {
+			return proposal, nil
+		}
+	}
+
+	return Proposal{}, ErrNotFound
+}
+
+// ClaimProposal marks a proposal with the status from as being approved, so
+// that only one approval of it can be made in Sirius at a time. The claim must
+// be finished with DecideProposal or CompleteProposal, or given up with
+// ReleaseProposal.
+func (s *Store) ClaimProposal(ctx context.Context, id int, from Status) error {
+	return s.setProposal(id, func(proposal *Proposal) {
+		proposal.Status = StatusApproving
+	}, from)
+}
+
+// ReleaseProposal returns a proposal that could not be approved to the status
+// it was claimed from.
+func (s *Store) ReleaseProposal(ctx context.Context, id int, to Status) error {
+	return s.setProposal(id, func(proposal *Proposal) {
+		proposal.Status = to
+	}, StatusApproving)
+}
+
+// decidedFrom lists the statuses a proposal can be decided from.
+var decidedFrom = map[Status][]Status{
+	StatusApplied:   {StatusApproving},
+	StatusApproved:  {StatusPending},
+	StatusRejected:  {StatusPending},
+	StatusCancelled: {StatusPending, StatusApproved},
+}
+
+// DecideProposal records that a claimed proposal has been applied, that a
+// pending proposal has been approved to be made when it is due or rejected,
+// or that a proposal that has not been made has been cancelled.
+func (s *Store) DecideProposal(ctx context.Context, id int, status Status, by User, at time.Time, comment string) error {
+	return s.setProposal(id, func(proposal *Proposal) {
+		proposal.Status = status
+		proposal.DecidedBy = by
+		proposal.DecidedAt = at
+		proposal.Comment = comment
+		if status == StatusApplied {
+			proposal.AppliedAt = at
+		}
+	}, decidedFrom[status]...)
+}
+
+// CompleteProposal records the outcome of making a claimed proposal that was
+// approved earlier, leaving who approved it unchanged.
+func (s *Store) CompleteProposal(ctx context.Context, id int, at time.Time, err error) error {
+	return s.setProposal(id, func(proposal *Proposal) {
+		if err != nil {
+			proposal.Status = StatusFailed
+			proposal.Error = err.Error()
+		} else {
+			proposal.Status = StatusApplied
+			proposal.AppliedAt = at
+		}
+	}, StatusApproving)
+}
+
+// setProposal applies fn to the proposal if it has one of the statuses from,
+// returning ErrNotPending if it does not.
+func (s *Store) setProposal(id int, fn func(*Proposal), from ...Status) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.state.Proposals, func(proposal Proposal) bool {
+		return proposal.ID == id
+	})
+	if i == -1 {
+		return ErrNotFound
+	}
+	if !slices.Contains(from, s.state.Proposals[i].Status) {
+		return ErrNotPending
+	}
+
+	return s.update(func(st *state) {
+		fn(&st.Proposals[i])
+	})
+}
+
+// update applies fn to a copy of the state and saves it, so that the state is
+// left unchanged if it cannot be written. It must be called with mu held.
+func (s *Store) update(fn func(*state)) error {
+	next := state{
+		LastID:    s.state.LastID,
+		Changes:   slices.Clone(s.state.Changes),
+		Proposals: slices.Clone(s.state.Proposals),
+	}
+	fn(&next)
+
+	if s.path != "" {
+		if err := s.save(next); err != nil {
+			return err
+		}
+	}
+
+	s.state = next
+	return nil
+}
+
+// save writes the state to a temporary file and moves it into place, so that
+// the file is never left partly written.
+func (s *Store) save(st state) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name()) //nolint:errcheck // the file will not exist once renamed
+
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), filepath.Clean(s.path))
+}
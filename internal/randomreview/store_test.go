@@ -0,0 +1,241 @@
+package randomreview
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreChanges(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "random-reviews.json")
+
+	store, err := NewStore(path)
+	assert.Nil(err)
+
+	changes, err := store.Changes(ctx)
+	assert.Nil(err)
+	assert.Empty(changes)
+
+	first := Change{
+		Time:   time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+		By:     User{ID: 1, Name: "Anne Able", Email: "a@example.com"},
+		Before: sirius.RandomReviews{LayPercentage: 10, ReviewCycle: 1},
+		After:  sirius.RandomReviews{LayPercentage: 20, ReviewCycle: 1},
+		Reason: "Audit recommendation",
+	}
+	second := Change{
+		Time:   time.Date(2021, 2, 2, 3, 4, 5, 0, time.UTC),
+		Before: sirius.RandomReviews{LayPercentage: 20, ReviewCycle: 1},
+		After:  sirius.RandomReviews{LayPercentage: 20, ReviewCycle: 2},
+	}
+
+	assert.Nil(store.RecordChange(ctx, first))
+	assert.Nil(store.RecordChange(ctx, second))
+
+	first.ID = 1
+	second.ID = 2
+
+	changes, err = store.Changes(ctx)
+	assert.Nil(err)
+	assert.Equal([]Change{second, first}, changes)
+
+	reopened, err := NewStore(path)
+	assert.Nil(err)
+
+	changes, err = reopened.Changes(ctx)
+	assert.Nil(err)
+	assert.Equal([]Change{second, first}, changes)
+}
+
+func TestStoreInMemory(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	store, err := NewStore("")
+	assert.Nil(err)
+
+	assert.Nil(store.RecordChange(ctx, Change{Reason: "test"}))
+
+	changes, err := store.Changes(ctx)
+	assert.Nil(err)
+	assert.Equal([]Change{{ID: 1, Reason: "test"}}, changes)
+}
+
+func TestStoreInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "random-reviews.json")
+	assert.Nil(t, os.WriteFile(path, []byte("not json"), 0600))
+
+	_, err := NewStore(path)
+	assert.NotNil(t, err)
+}
+
+func TestStoreUnchangedWhenSaveFails(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	store, err := NewStore(filepath.Join(t.TempDir(), "missing", "random-reviews.json"))
+	assert.Nil(err)
+
+	assert.NotNil(store.RecordChange(ctx, Change{Reason: "test"}))
+
+	changes, _ := store.Changes(ctx)
+	assert.Empty(changes)
+}
+
+func TestStoreProposals(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "random-reviews.json")
+	store, _ := NewStore(path)
+
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	first, err := store.Propose(ctx, Proposal{
+		ProposedBy: User{ID: 1},
+		Settings:   sirius.EditRandomReview{LayPercentage: "20"},
+		Reason:     "first",
+	})
+	assert.Nil(err)
+	assert.Equal(1, first.ID)
+	assert.Equal(StatusPending, first.Status)
+
+	second, _ := store.Propose(ctx, Proposal{ProposedBy: User{ID: 1}, Reason: "second", EffectiveAt: now.AddDate(0, 1, 0)})
+
+	assert.Nil(store.DecideProposal(ctx, first.ID, StatusRejected, User{ID: 2}, now, "Not agreed"))
+	assert.Equal(ErrNotPending, store.DecideProposal(ctx, first.ID, StatusApplied, User{ID: 2}, now, ""))
+	assert.Equal(ErrNotPending, store.ClaimProposal(ctx, first.ID, StatusPending))
+	assert.Equal(ErrNotFound, store.DecideProposal(ctx, 99, StatusApplied, User{ID: 2}, now, ""))
+	assert.Equal(ErrNotFound, store.ClaimProposal(ctx, 99, StatusPending))
+
+	_, err = store.Proposal(ctx, 99)
+	assert.Equal(ErrNotFound, err)
+
+	reopened, err := NewStore(path)
+	assert.Nil(err)
+
+	proposals, err := reopened.Proposals(ctx)
+	assert.Nil(err)
+	assert.Len(proposals, 2)
+	assert.Equal(second, proposals[0])
+
+	rejected, err := reopened.Proposal(ctx, first.ID)
+	assert.Nil(err)
+	assert.Equal(StatusRejected, rejected.Status)
+	assert.Equal(User{ID: 2}, rejected.DecidedBy)
+	assert.Equal("Not agreed", rejected.Comment)
+	assert.True(now.Equal(rejected.DecidedAt))
+}
+
+func TestProposalDue(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.True(t, Proposal{}.Due(now))
+	assert.True(t, Proposal{EffectiveAt: now}.Due(now))
+	assert.False(t, Proposal{EffectiveAt: now.Add(time.Minute)}.Due(now))
+}
+
+func TestStoreClaimProposal(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	store, _ := NewStore("")
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	proposal, _ := store.Propose(ctx, Proposal{ProposedBy: User{ID: 1}})
+
+	assert.Equal(ErrNotPending, store.DecideProposal(ctx, proposal.ID, StatusApplied, User{ID: 2}, now, ""))
+	assert.Equal(ErrNotPending, store.ReleaseProposal(ctx, proposal.ID, StatusPending))
+
+	assert.Nil(store.ClaimProposal(ctx, proposal.ID, StatusPending))
+	assert.Equal(ErrNotPending, store.ClaimProposal(ctx, proposal.ID, StatusPending))
+	assert.Equal(ErrNotPending, store.DecideProposal(ctx, proposal.ID, StatusRejected, User{ID: 3}, now, "No"))
+
+	assert.Nil(store.ReleaseProposal(ctx, proposal.ID, StatusPending))
+	released, _ := store.Proposal(ctx, proposal.ID)
+	assert.Equal(StatusPending, released.Status)
+
+	assert.Nil(store.ClaimProposal(ctx, proposal.ID, StatusPending))
+	assert.Nil(store.DecideProposal(ctx, proposal.ID, StatusApplied, User{ID: 2}, now, ""))
+
+	applied, _ := store.Proposal(ctx, proposal.ID)
+	assert.Equal(StatusApplied, applied.Status)
+	assert.Equal(User{ID: 2}, applied.DecidedBy)
+	assert.True(now.Equal(applied.AppliedAt))
+}
+
+func TestStoreApprovedProposal(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	store, _ := NewStore("")
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+
+	proposal, _ := store.Propose(ctx, Proposal{ProposedBy: User{ID: 1}, EffectiveAt: later})
+
+	assert.Nil(store.DecideProposal(ctx, proposal.ID, StatusApproved, User{ID: 2}, now, "Agreed"))
+	assert.Equal(ErrNotPending, store.DecideProposal(ctx, proposal.ID, StatusRejected, User{ID: 3}, now, "No"))
+	assert.Equal(ErrNotPending, store.ClaimProposal(ctx, proposal.ID, StatusPending))
+
+	assert.Nil(store.ClaimProposal(ctx, proposal.ID, StatusApproved))
+	assert.Equal(ErrNotPending, store.DecideProposal(ctx, proposal.ID, StatusCancelled, User{ID: 1}, now, ""))
+
+	assert.Nil(store.ReleaseProposal(ctx, proposal.ID, StatusApproved))
+	assert.Nil(store.ClaimProposal(ctx, proposal.ID, StatusApproved))
+	assert.Nil(store.CompleteProposal(ctx, proposal.ID, later, nil))
+	assert.Equal(ErrNotPending, store.CompleteProposal(ctx, proposal.ID, later, nil))
+
+	applied, _ := store.Proposal(ctx, proposal.ID)
+	assert.Equal(StatusApplied, applied.Status)
+	assert.Equal(User{ID: 2}, applied.DecidedBy)
+	assert.True(now.Equal(applied.DecidedAt))
+	assert.True(later.Equal(applied.AppliedAt))
+	assert.Equal("Agreed", applied.Comment)
+
+	failing, _ := store.Propose(ctx, Proposal{ProposedBy: User{ID: 1}, EffectiveAt: later})
+	assert.Nil(store.DecideProposal(ctx, failing.ID, StatusApproved, User{ID: 2}, now, ""))
+	assert.Nil(store.ClaimProposal(ctx, failing.ID, StatusApproved))
+	assert.Nil(store.CompleteProposal(ctx, failing.ID, later, errors.New("rejected by Sirius")))
+
+	failed, _ := store.Proposal(ctx, failing.ID)
+	assert.Equal(StatusFailed, failed.Status)
+	assert.Equal("rejected by Sirius", failed.Error)
+	assert.True(failed.AppliedAt.IsZero())
+
+	cancelling, _ := store.Propose(ctx, Proposal{ProposedBy: User{ID: 1}, EffectiveAt: later})
+	assert.Nil(store.DecideProposal(ctx, cancelling.ID, StatusApproved, User{ID: 2}, now, ""))
+	assert.Nil(store.DecideProposal(ctx, cancelling.ID, StatusCancelled, User{ID: 1}, now, ""))
+	assert.Equal(ErrNotPending, store.ClaimProposal(ctx, cancelling.ID, StatusApproved))
+}
+
+func TestStoreClaimProposalConcurrently(t *testing.T) {
+	ctx := context.Background()
+
+	store, _ := NewStore("")
+	proposal, _ := store.Propose(ctx, Proposal{ProposedBy: User{ID: 1}})
+
+	var wg sync.WaitGroup
+	var claimed atomic.Int32
+	for range 10 {
+		wg.Go(func() {
+			if store.ClaimProposal(ctx, proposal.ID, StatusPending) == nil {
+				claimed.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), claimed.Load())
+}
@@ -62,6 +62,13 @@ func addTeam(client AddTeamClient, tmpl Template) Handler {
 			}
 
 			id, err := client.AddTeam(ctx, name, teamType, phone, email)
+			recordAudit(r, "add-team", fmt.Sprintf("team:%d", id), nil, sirius.Team{
+				ID:          id,
+				DisplayName: name,
+				Type:        teamType,
+				PhoneNumber: phone,
+				Email:       email,
+			}, err)
 
 			if verr, ok := err.(sirius.ValidationError); ok {
 				teamTypes, err := client.TeamTypes(ctx)
@@ -1,9 +1,11 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
 )
@@ -22,6 +24,7 @@ type addTeamMemberVars struct {
 	Users     []sirius.User
 	Members   map[int]bool
 	Success   string
+	Unmatched []string
 	Errors    sirius.ValidationErrors
 }
 
@@ -54,29 +57,82 @@ func addTeamMember(client AddTeamMemberClient, tmpl Template) Handler {
 		}
 
 		if r.Method == http.MethodPost {
-			memberID, err := strconv.Atoi(r.PostFormValue("id"))
-			if err != nil {
-				return err
+			var memberIDs []int
+
+			if id := r.PostFormValue("id"); id != "" {
+				memberID, err := strconv.Atoi(id)
+				if err != nil {
+					return StatusError(http.StatusBadRequest)
+				}
+
+				memberIDs = append(memberIDs, memberID)
 			}
 
-			team.Members = append(team.Members, sirius.TeamMember{ID: memberID})
+			for _, id := range r.PostForm["selected[]"] {
+				memberID, err := strconv.Atoi(id)
+				if err != nil {
+					return StatusError(http.StatusBadRequest)
+				}
 
-			err = client.EditTeam(ctx, team)
+				memberIDs = append(memberIDs, memberID)
+			}
 
-			if _, ok := err.(sirius.ClientError); ok {
+			for _, email := range splitEmails(r.PostFormValue("emails")) {
+				memberID, err := findUserIDByEmail(ctx, client, email)
+				if err != nil {
+					return err
+				}
+
+				if memberID == 0 {
+					vars.Unmatched = append(vars.Unmatched, email)
+				} else {
+					memberIDs = append(memberIDs, memberID)
+				}
+			}
+
+			existing := map[int]bool{}
+			for _, member := range team.Members {
+				existing[member.ID] = true
+			}
+
+			before := team
+			added := 0
+			for _, memberID := range memberIDs {
+				if !existing[memberID] {
+					team.Members = append(team.Members, sirius.TeamMember{ID: memberID})
+					existing[memberID] = true
+					added++
+				}
+			}
+
+			if added > 0 {
+				err = client.EditTeam(ctx, team)
+				recordAudit(r, "add-team-member", fmt.Sprintf("team:%d", team.ID), before, team, err)
+
+				if _, ok := err.(sirius.ClientError); ok {
+					vars.Errors = sirius.ValidationErrors{
+						"search": {
+							"": err.Error(),
+						},
+					}
+					w.WriteHeader(http.StatusBadRequest)
+				} else if verr, ok := err.(sirius.ValidationError); ok {
+					vars.Errors = verr.Errors
+					w.WriteHeader(http.StatusBadRequest)
+				} else if err != nil {
+					return err
+				} else if email := r.PostFormValue("email"); email != "" && added == 1 {
+					vars.Success = email
+				} else if added == 1 {
+					vars.Success = "1 user"
+				} else {
+					vars.Success = fmt.Sprintf("%d users", added)
+				}
+			} else if len(memberIDs) == 0 && len(vars.Unmatched) == 0 {
 				vars.Errors = sirius.ValidationErrors{
-					"search": {
-						"": err.Error(),
-					},
+					"#": {"": "Select at least one user to add to the team"},
 				}
 				w.WriteHeader(http.StatusBadRequest)
-			} else if verr, ok := err.(sirius.ValidationError); ok {
-				vars.Errors = verr.Errors
-				w.WriteHeader(http.StatusBadRequest)
-			} else if err != nil {
-				return err
-			} else {
-				vars.Success = r.PostFormValue("email")
 			}
 		}
 
@@ -107,3 +163,26 @@ func addTeamMember(client AddTeamMemberClient, tmpl Template) Handler {
 		return tmpl.ExecuteTemplate(w, "page", vars)
 	}
 }
+
+func splitEmails(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	})
+}
+
+func findUserIDByEmail(ctx sirius.Context, client AddTeamMemberClient, email string) (int, error) {
+	users, err := client.SearchUsers(ctx, email)
+	if _, ok := err.(sirius.ClientError); ok {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	for _, user := range users {
+		if strings.EqualFold(user.Email, email) {
+			return user.ID, nil
+		}
+	}
+
+	return 0, nil
+}
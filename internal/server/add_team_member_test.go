@@ -409,3 +409,140 @@ func TestPutAddTeamMemberTeam(t *testing.T) {
 	err := addTeamMember(nil, nil)(client.requiredPermissions(), w, r)
 	assert.Equal(StatusError(http.StatusMethodNotAllowed), err)
 }
+
+func TestPostAddTeamMemberSelected(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockAddTeamMemberClient{}
+	client.team.data = sirius.Team{
+		Members: []sirius.TeamMember{
+			{ID: 4},
+		},
+	}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/teams/add-member/123", strings.NewReader("selected[]=4&selected[]=5&selected[]=6&selected[]=5"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := addTeamMember(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(1, client.editTeam.count)
+	assert.Equal(sirius.Team{
+		Members: []sirius.TeamMember{
+			{ID: 4},
+			{ID: 5},
+			{ID: 6},
+		},
+	}, client.editTeam.lastTeam)
+
+	assert.Equal(0, client.searchUsers.count)
+
+	assert.Equal(addTeamMemberVars{
+		Path:    "/teams/add-member/123",
+		Team:    client.team.data,
+		Success: "2 users",
+	}, template.lastVars)
+}
+
+func TestPostAddTeamMemberEmails(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockAddTeamMemberClient{}
+	client.team.data = sirius.Team{
+		Members: []sirius.TeamMember{
+			{ID: 4},
+		},
+	}
+	client.searchUsers.data = []sirius.User{
+		{ID: 7, Email: "Seven@example.com"},
+		{ID: 8, Email: "eight@example.com"},
+	}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/teams/add-member/123", strings.NewReader("emails=seven%40example.com%0D%0Amissing%40example.com"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := addTeamMember(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(2, client.searchUsers.count)
+	assert.Equal("missing@example.com", client.searchUsers.lastSearch)
+
+	assert.Equal(1, client.editTeam.count)
+	assert.Equal(sirius.Team{
+		Members: []sirius.TeamMember{
+			{ID: 4},
+			{ID: 7},
+		},
+	}, client.editTeam.lastTeam)
+
+	assert.Equal(addTeamMemberVars{
+		Path:      "/teams/add-member/123",
+		Team:      client.team.data,
+		Success:   "1 user",
+		Unmatched: []string{"missing@example.com"},
+	}, template.lastVars)
+}
+
+func TestPostAddTeamMemberEmailsNoneMatched(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockAddTeamMemberClient{}
+	client.searchUsers.err = sirius.ClientError("Search term must be at least three characters")
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/teams/add-member/123", strings.NewReader("emails=a%40"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := addTeamMember(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(0, client.editTeam.count)
+	assert.Equal(addTeamMemberVars{
+		Path:      "/teams/add-member/123",
+		Unmatched: []string{"a@"},
+	}, template.lastVars)
+}
+
+func TestPostAddTeamMemberEmailsSearchError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedError := errors.New("oops")
+
+	client := &mockAddTeamMemberClient{}
+	client.searchUsers.err = expectedError
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/teams/add-member/123", strings.NewReader("emails=a%40example.com"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := addTeamMember(client, template)(client.requiredPermissions(), w, r)
+	assert.Equal(expectedError, err)
+	assert.Equal(0, client.editTeam.count)
+	assert.Equal(0, template.count)
+}
+
+func TestPostAddTeamMemberNothingSelected(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockAddTeamMemberClient{}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/teams/add-member/123", strings.NewReader("emails="))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := addTeamMember(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(http.StatusBadRequest, w.Result().StatusCode)
+	assert.Equal(0, client.editTeam.count)
+	assert.Equal(sirius.ValidationErrors{
+		"#": {"": "Select at least one user to add to the team"},
+	}, template.lastVars.(addTeamMemberVars).Errors)
+}
@@ -52,6 +52,13 @@ func addUser(client AddUserClient, tmpl Template) Handler {
 			)
 
 			err := client.AddUser(ctx, email, firstname, surname, organisation, roles)
+			recordAudit(r, "add-user", "user:"+email, nil, sirius.AuthUser{
+				Email:        email,
+				Firstname:    firstname,
+				Surname:      surname,
+				Organisation: organisation,
+				Roles:        roles,
+			}, err)
 
 			if verr, ok := err.(sirius.ValidationError); ok {
 				vars.Errors = verr.Errors
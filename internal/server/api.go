@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ministryofjustice/opg-go-common/telemetry"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+type apiErrorResponse struct {
+	Code     int                     `json:"code"`
+	Message  string                  `json:"message"`
+	Errors   sirius.ValidationErrors `json:"errors,omitempty"`
+	Upstream *sirius.StatusError     `json:"upstream,omitempty"`
+}
+
+// apiErrorHandler is the equivalent of errorHandler for the JSON API, writing
+// errors as JSON rather than rendering the error page.
+func apiErrorHandler(client ErrorHandlerClient) func(next Handler) http.Handler {
+	return func(next Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			myPermissions, err := client.MyPermissions(getContext(r))
+
+			if err == nil {
+				err = next(myPermissions, w, r)
+				recordHandlerMetrics(r, err)
+			}
+
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					w.WriteHeader(499)
+					return
+				}
+
+				resp := apiErrorResponse{
+					Code:    http.StatusInternalServerError,
+					Message: err.Error(),
+				}
+
+				var (
+					status StatusError
+					verr   sirius.ValidationError
+					serr   sirius.StatusError
+					cerr   sirius.ClientError
+				)
+
+				switch {
+				case errors.As(err, &status):
+					resp.Code = status.Code()
+					resp.Message = http.StatusText(status.Code())
+				case errors.Is(err, sirius.ErrUnauthorized):
+					resp.Code = http.StatusUnauthorized
+				case errors.As(err, &verr):
+					resp.Code = http.StatusBadRequest
+					resp.Errors = verr.Errors
+				case errors.As(err, &serr):
+					resp.Code = http.StatusBadGateway
+					if serr.Code == http.StatusForbidden || serr.Code == http.StatusNotFound {
+						resp.Code = serr.Code
+					}
+					resp.Message = serr.Title()
+					resp.Upstream = &serr
+				case errors.As(err, &cerr):
+					resp.Code = http.StatusBadRequest
+				case errors.Is(err, sirius.ErrUnavailable):
+					resp.Code = http.StatusServiceUnavailable
+				}
+
+				if resp.Code >= http.StatusInternalServerError {
+					telemetry.LoggerFromContext(r.Context()).Error(err.Error())
+				}
+
+				if err := writeJSON(w, resp.Code, resp); err != nil {
+					telemetry.LoggerFromContext(r.Context()).Error("could not write error response", slog.Any("err", err.Error()))
+				}
+			}
+		})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	return json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+type apiMyDetailsResponse struct {
+	ID                 int      `json:"id"`
+	Firstname          string   `json:"firstname"`
+	Surname            string   `json:"surname"`
+	Email              string   `json:"email"`
+	PhoneNumber        string   `json:"phoneNumber"`
+	Organisation       string   `json:"organisation"`
+	Roles              []string `json:"roles"`
+	Teams              []string `json:"teams"`
+	CanEditPhoneNumber bool     `json:"canEditPhoneNumber"`
+}
+
+func apiMyDetails(client MyDetailsClient) Handler {
+	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		if r.Method != http.MethodGet {
+			return StatusError(http.StatusMethodNotAllowed)
+		}
+
+		myDetails, err := client.MyDetails(getContext(r))
+		if err != nil {
+			return err
+		}
+
+		resp := apiMyDetailsResponse{
+			ID:                 myDetails.ID,
+			Firstname:          myDetails.Firstname,
+			Surname:            myDetails.Surname,
+			Email:              myDetails.Email,
+			PhoneNumber:        myDetails.PhoneNumber,
+			Roles:              []string{},
+			Teams:              []string{},
+			CanEditPhoneNumber: perm.HasPermission("v1-users-updatetelephonenumber", http.MethodPut),
+		}
+
+		for _, role := range myDetails.Roles {
+			if role == "OPG User" || role == "COP User" {
+				resp.Organisation = role
+			} else {
+				resp.Roles = append(resp.Roles, role)
+			}
+		}
+
+		for _, team := range myDetails.Teams {
+			resp.Teams = append(resp.Teams, team.DisplayName)
+		}
+
+		return writeJSON(w, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIMyDetails(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockMyDetailsClient{
+		data: sirius.MyDetails{
+			ID:          123,
+			Firstname:   "John",
+			Surname:     "Doe",
+			Email:       "john@doe.com",
+			PhoneNumber: "123",
+			Roles:       []string{"A", "COP User", "B"},
+			Teams:       []sirius.MyDetailsTeam{{DisplayName: "A Team"}},
+		},
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/api/my-details", nil)
+
+	perm := sirius.PermissionSet{"v1-users-updatetelephonenumber": sirius.PermissionGroup{Permissions: []string{"put"}}}
+
+	err := apiMyDetails(client)(perm, w, r)
+	assert.Nil(err)
+
+	assert.Equal(1, client.count)
+	assert.Equal(getContext(r), client.lastCtx)
+	assert.JSONEq(`{
+		"id": 123,
+		"firstname": "John",
+		"surname": "Doe",
+		"email": "john@doe.com",
+		"phoneNumber": "123",
+		"organisation": "COP User",
+		"roles": ["A", "B"],
+		"teams": ["A Team"],
+		"canEditPhoneNumber": true
+	}`, w.Body.String())
+}
+
+func TestAPIMyDetailsBadMethod(t *testing.T) {
+	assert := assert.New(t)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/api/my-details", nil)
+
+	err := apiMyDetails(nil)(sirius.PermissionSet{}, w, r)
+	assert.Equal(StatusError(http.StatusMethodNotAllowed), err)
+}
+
+func TestAPIMyDetailsError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedErr := errors.New("oops")
+	client := &mockMyDetailsClient{err: expectedErr}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/api/my-details", nil)
+
+	err := apiMyDetails(client)(sirius.PermissionSet{}, w, r)
+	assert.Equal(expectedErr, err)
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+func apiRandomReviews(client RandomReviewsClient) Handler {
+	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		if !perm.HasPermission("v1-random-review-settings", http.MethodGet) {
+			return StatusError(http.StatusForbidden)
+		}
+
+		if r.Method != http.MethodGet {
+			return StatusError(http.StatusMethodNotAllowed)
+		}
+
+		randomReviews, err := client.RandomReviews(getContext(r))
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(w, http.StatusOK, randomReviews)
+	}
+}
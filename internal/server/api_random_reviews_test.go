@@ -0,0 +1,51 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRandomReviews(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockRandomReviewsClient{
+		data: sirius.RandomReviews{LayPercentage: 20, PaPercentage: 30, ProPercentage: 40, ReviewCycle: 3},
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/api/random-reviews", nil)
+
+	err := apiRandomReviews(client)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(1, client.count)
+	assert.JSONEq(`{"layPercentage":20,"paPercentage":30,"proPercentage":40,"reviewCycle":3}`, w.Body.String())
+}
+
+func TestAPIRandomReviewsNoPermission(t *testing.T) {
+	assert := assert.New(t)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/api/random-reviews", nil)
+
+	err := apiRandomReviews(nil)(sirius.PermissionSet{}, w, r)
+	assert.Equal(StatusError(http.StatusForbidden), err)
+}
+
+func TestAPIRandomReviewsError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedErr := errors.New("oops")
+	client := &mockRandomReviewsClient{err: expectedErr}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/api/random-reviews", nil)
+
+	err := apiRandomReviews(client)(client.requiredPermissions(), w, r)
+	assert.Equal(expectedErr, err)
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+type apiTeam struct {
+	ID          int             `json:"id"`
+	DisplayName string          `json:"displayName"`
+	Type        string          `json:"type"`
+	TypeLabel   string          `json:"typeLabel"`
+	Email       string          `json:"email"`
+	PhoneNumber string          `json:"phoneNumber"`
+	Members     []apiTeamMember `json:"members"`
+}
+
+type apiTeamMember struct {
+	ID          int    `json:"id"`
+	DisplayName string `json:"displayName"`
+	Email       string `json:"email"`
+}
+
+func newAPITeam(team sirius.Team) apiTeam {
+	t := apiTeam{
+		ID:          team.ID,
+		DisplayName: team.DisplayName,
+		Type:        team.Type,
+		TypeLabel:   team.TypeLabel,
+		Email:       team.Email,
+		PhoneNumber: team.PhoneNumber,
+		Members:     []apiTeamMember{},
+	}
+
+	for _, member := range team.Members {
+		t.Members = append(t.Members, apiTeamMember{
+			ID:          member.ID,
+			DisplayName: member.DisplayName,
+			Email:       member.Email,
+		})
+	}
+
+	return t
+}
+
+func apiListTeams(client ListTeamsClient) Handler {
+	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		if !perm.HasPermission("v1-teams", http.MethodPut) {
+			return StatusError(http.StatusForbidden)
+		}
+
+		if r.Method != http.MethodGet {
+			return StatusError(http.StatusMethodNotAllowed)
+		}
+
+		teams, err := client.Teams(getContext(r))
+		if err != nil {
+			return err
+		}
+
+		search := strings.ToLower(r.FormValue("search"))
+
+		resp := []apiTeam{}
+		for _, team := range teams {
+			if strings.Contains(strings.ToLower(team.DisplayName), search) {
+				resp = append(resp, newAPITeam(team))
+			}
+		}
+
+		return writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func apiViewTeam(client ViewTeamClient) Handler {
+	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		if !perm.HasPermission("v1-teams", http.MethodPut) {
+			return StatusError(http.StatusForbidden)
+		}
+
+		if r.Method != http.MethodGet {
+			return StatusError(http.StatusMethodNotAllowed)
+		}
+
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/teams/"))
+		if err != nil {
+			return StatusError(http.StatusNotFound)
+		}
+
+		team, err := client.Team(getContext(r), id)
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(w, http.StatusOK, newAPITeam(team))
+	}
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIListTeams(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockListTeamsClient{
+		data: []sirius.Team{
+			{
+				ID:          29,
+				DisplayName: "Cool Team",
+				Type:        "ALLOCATIONS",
+				TypeLabel:   "Supervision — Allocations",
+				Members:     []sirius.TeamMember{{ID: 1, DisplayName: "Milo Nihei", Email: "milo@example.com"}},
+			},
+			{
+				ID:          30,
+				DisplayName: "Other Team",
+				TypeLabel:   "LPA",
+			},
+		},
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/api/teams?search=cool", nil)
+
+	err := apiListTeams(client)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(1, client.count)
+	assert.Equal(getContext(r), client.lastCtx)
+
+	assert.Equal(http.StatusOK, w.Result().StatusCode)
+	assert.JSONEq(`[{
+		"id": 29,
+		"displayName": "Cool Team",
+		"type": "ALLOCATIONS",
+		"typeLabel": "Supervision — Allocations",
+		"email": "",
+		"phoneNumber": "",
+		"members": [{"id": 1, "displayName": "Milo Nihei", "email": "milo@example.com"}]
+	}]`, w.Body.String())
+}
+
+func TestAPIListTeamsNoPermission(t *testing.T) {
+	assert := assert.New(t)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/api/teams", nil)
+
+	err := apiListTeams(nil)(sirius.PermissionSet{}, w, r)
+	assert.Equal(StatusError(http.StatusForbidden), err)
+}
+
+func TestAPIListTeamsError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedErr := errors.New("oops")
+	client := &mockListTeamsClient{err: expectedErr}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/api/teams", nil)
+
+	err := apiListTeams(client)(client.requiredPermissions(), w, r)
+	assert.Equal(expectedErr, err)
+}
+
+func TestAPIViewTeam(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockViewTeamClient{
+		data: sirius.Team{ID: 5, DisplayName: "Cool Team", TypeLabel: "LPA", Email: "cool@example.com", PhoneNumber: "0123"},
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/api/teams/5", nil)
+
+	err := apiViewTeam(client)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(5, client.lastRequestID)
+	assert.JSONEq(`{
+		"id": 5,
+		"displayName": "Cool Team",
+		"type": "",
+		"typeLabel": "LPA",
+		"email": "cool@example.com",
+		"phoneNumber": "0123",
+		"members": []
+	}`, w.Body.String())
+}
+
+func TestAPIViewTeamBadPath(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockViewTeamClient{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/api/teams/hello", nil)
+
+	err := apiViewTeam(client)(client.requiredPermissions(), w, r)
+	assert.Equal(StatusError(http.StatusNotFound), err)
+	assert.Equal(0, client.count)
+}
+
+func TestAPIViewTeamNoPermission(t *testing.T) {
+	assert := assert.New(t)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/api/teams/5", nil)
+
+	err := apiViewTeam(nil)(sirius.PermissionSet{}, w, r)
+	assert.Equal(StatusError(http.StatusForbidden), err)
+}
+
+func TestAPIViewTeamError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedErr := sirius.StatusError{Code: http.StatusNotFound}
+	client := &mockViewTeamClient{err: expectedErr}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/api/teams/5", nil)
+
+	err := apiViewTeam(client)(client.requiredPermissions(), w, r)
+	assert.Equal(expectedErr, err)
+}
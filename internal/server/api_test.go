@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIErrorHandler(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err      error
+		code     int
+		expected string
+	}{
+		"status": {
+			err:      StatusError(http.StatusForbidden),
+			code:     http.StatusForbidden,
+			expected: `{"code":403,"message":"Forbidden"}`,
+		},
+		"unauthorized": {
+			err:      sirius.ErrUnauthorized,
+			code:     http.StatusUnauthorized,
+			expected: `{"code":401,"message":"unauthorized"}`,
+		},
+		"validation": {
+			err: sirius.ValidationError{
+				Message: "Invalid",
+				Errors:  sirius.ValidationErrors{"search": {"tooShort": "Too short"}},
+			},
+			code:     http.StatusBadRequest,
+			expected: `{"code":400,"message":"Invalid","errors":{"search":{"tooShort":"Too short"}}}`,
+		},
+		"client": {
+			err:      sirius.ClientError("Search term must be at least three characters"),
+			code:     http.StatusBadRequest,
+			expected: `{"code":400,"message":"Search term must be at least three characters"}`,
+		},
+		"upstream not found": {
+			err:      sirius.StatusError{Code: http.StatusNotFound, URL: "http://sirius/api/v1/teams/5", Method: http.MethodGet},
+			code:     http.StatusNotFound,
+			expected: `{"code":404,"message":"unexpected response from Sirius","upstream":{"code":404,"url":"http://sirius/api/v1/teams/5","method":"GET"}}`,
+		},
+		"upstream error": {
+			err:      sirius.StatusError{Code: http.StatusTeapot, URL: "http://sirius/api/v1/teams", Method: http.MethodGet},
+			code:     http.StatusBadGateway,
+			expected: `{"code":502,"message":"unexpected response from Sirius","upstream":{"code":418,"url":"http://sirius/api/v1/teams","method":"GET"}}`,
+		},
+		"unavailable": {
+			err:      fmt.Errorf("%w: connection refused", sirius.ErrUnavailable),
+			code:     http.StatusServiceUnavailable,
+			expected: `{"code":503,"message":"Sirius is unavailable: connection refused"}`,
+		},
+		"other": {
+			err:      errors.New("oops"),
+			code:     http.StatusInternalServerError,
+			expected: `{"code":500,"message":"oops"}`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			ctx, _ := contextWithLogger()
+			client := &mockErrorHandlerClient{}
+
+			handler := apiErrorHandler(client)(func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+				return tc.err
+			})
+
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequestWithContext(ctx, "GET", "/api/teams", nil)
+
+			handler.ServeHTTP(w, r)
+
+			resp := w.Result()
+			assert.Equal(tc.code, resp.StatusCode)
+			assert.Equal("application/json", resp.Header.Get("Content-Type"))
+			assert.JSONEq(tc.expected, w.Body.String())
+		})
+	}
+}
+
+func TestAPIErrorHandlerMyPermissionsError(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockErrorHandlerClient{err: sirius.ErrUnauthorized}
+
+	handler := apiErrorHandler(client)(func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/api/teams", nil)
+
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(1, client.count)
+	assert.Equal(http.StatusUnauthorized, w.Result().StatusCode)
+}
+
+func TestAPIErrorHandlerCancelled(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockErrorHandlerClient{}
+
+	handler := apiErrorHandler(client)(func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		return context.Canceled
+	})
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/api/teams", nil)
+
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(499, w.Result().StatusCode)
+	assert.Equal("", w.Body.String())
+}
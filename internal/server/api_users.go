@@ -0,0 +1,53 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+type apiUser struct {
+	ID          int               `json:"id"`
+	DisplayName string            `json:"displayName"`
+	Email       string            `json:"email"`
+	Status      string            `json:"status"`
+	Teams       []sirius.UserTeam `json:"teams"`
+}
+
+func apiListUsers(client ListUsersClient) Handler {
+	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		if !perm.HasPermission("v1-users", http.MethodPut) {
+			return StatusError(http.StatusForbidden)
+		}
+
+		if r.Method != http.MethodGet {
+			return StatusError(http.StatusMethodNotAllowed)
+		}
+
+		team, _ := strconv.Atoi(r.FormValue("team"))
+
+		users, err := client.SearchUsers(getContext(r), r.FormValue("search"))
+		if err != nil {
+			return err
+		}
+
+		resp := []apiUser{}
+		for _, user := range filterUsersByTeam(users, team) {
+			teams := user.Teams
+			if teams == nil {
+				teams = []sirius.UserTeam{}
+			}
+
+			resp = append(resp, apiUser{
+				ID:          user.ID,
+				DisplayName: user.DisplayName,
+				Email:       user.Email,
+				Status:      user.Status.String(),
+				Teams:       teams,
+			})
+		}
+
+		return writeJSON(w, http.StatusOK, resp)
+	}
+}
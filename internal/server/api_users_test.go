@@ -0,0 +1,93 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIListUsers(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockListUsersClient{
+		data: []sirius.User{
+			{ID: 29, DisplayName: "Milo Nihei", Email: "milo@example.com", Status: "Active", Teams: []sirius.UserTeam{{ID: 1, DisplayName: "Cool Team"}}},
+			{ID: 30, DisplayName: "Anton Mccoy", Email: "anton@example.com", Status: "Suspended"},
+		},
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/api/users?search=milo", nil)
+
+	err := apiListUsers(client)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(1, client.count)
+	assert.Equal("milo", client.lastSearch)
+	assert.Equal(getContext(r), client.lastCtx)
+
+	assert.Equal(http.StatusOK, w.Result().StatusCode)
+	assert.JSONEq(`[
+		{"id":29,"displayName":"Milo Nihei","email":"milo@example.com","status":"Active","teams":[{"id":1,"displayName":"Cool Team"}]},
+		{"id":30,"displayName":"Anton Mccoy","email":"anton@example.com","status":"Suspended","teams":[]}
+	]`, w.Body.String())
+}
+
+func TestAPIListUsersFilteredByTeam(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockListUsersClient{
+		data: []sirius.User{
+			{ID: 29, DisplayName: "Milo Nihei", Status: "Active", Teams: []sirius.UserTeam{{ID: 1, DisplayName: "Cool Team"}}},
+			{ID: 30, DisplayName: "Anton Mccoy", Status: "Active"},
+		},
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/api/users?search=milo&team=2", nil)
+
+	err := apiListUsers(client)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.JSONEq(`[]`, w.Body.String())
+}
+
+func TestAPIListUsersNoPermission(t *testing.T) {
+	assert := assert.New(t)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/api/users", nil)
+
+	err := apiListUsers(nil)(sirius.PermissionSet{}, w, r)
+	assert.Equal(StatusError(http.StatusForbidden), err)
+}
+
+func TestAPIListUsersBadMethod(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockListUsersClient{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/api/users", nil)
+
+	err := apiListUsers(client)(client.requiredPermissions(), w, r)
+	assert.Equal(StatusError(http.StatusMethodNotAllowed), err)
+}
+
+func TestAPIListUsersError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedErr := errors.New("oops")
+	client := &mockListUsersClient{err: expectedErr}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/api/users?search=milo", nil)
+
+	err := apiListUsers(client)(client.requiredPermissions(), w, r)
+	assert.Equal(expectedErr, err)
+	assert.Equal("", w.Body.String())
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ministryofjustice/opg-go-common/telemetry"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/audit"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+type AuditClient interface {
+	MyDetails(sirius.Context) (sirius.MyDetails, error)
+}
+
+type auditorKey struct{}
+
+type auditor struct {
+	client AuditClient
+	sink   audit.Sink
+	now    func() time.Time
+	actor  *audit.Actor
+}
+
+// withAudit makes a sink available to handlers for the duration of a request,
+// so that they can record the changes they make with recordAudit.
+func withAudit(client AuditClient, sink audit.Sink) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if sink == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			a := &auditor{client: client, sink: sink, now: time.Now}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), auditorKey{}, a)))
+		})
+	}
+}
+
+func getAuditor(r *http.Request) *auditor {
+	a, _ := r.Context().Value(auditorKey{}).(*auditor)
+	return a
+}
+
+// auditing reports whether events recorded for the request will be kept. It
+// can be used to avoid fetching "before" state that would not be used.
+func auditing(r *http.Request) bool {
+	return getAuditor(r) != nil
+}
+
+func recordAudit(r *http.Request, action, target string, before, after interface{}, err error) {
+	a := getAuditor(r)
+	if a == nil {
+		return
+	}
+
+	event := audit.Event{
+		Time:    a.now().UTC(),
+		Action:  action,
+		Actor:   a.getActor(r),
+		Target:  target,
+		Before:  before,
+		After:   after,
+		Outcome: audit.OutcomeSuccess,
+	}
+
+	if verr, ok := err.(sirius.ValidationError); ok {
+		event.Outcome = audit.OutcomeRejected
+		event.Error = verr.Message
+		event.ValidationErrors = verr.Errors
+	} else if err != nil {
+		event.Outcome = audit.OutcomeError
+		event.Error = err.Error()
+	}
+
+	if err := a.sink.Record(r.Context(), event); err != nil {
+		telemetry.LoggerFromContext(r.Context()).Error("could not record audit event", slog.Any("err", err.Error()), slog.String("action", action))
+	}
+}
+
+func (a *auditor) getActor(r *http.Request) audit.Actor {
+	if a.actor != nil {
+		return *a.actor
+	}
+
+	actor := audit.Actor{}
+
+	myDetails, err := a.client.MyDetails(getContext(r))
+	if err != nil {
+		telemetry.LoggerFromContext(r.Context()).Error("could not identify user for audit event", slog.Any("err", err.Error()))
+	} else {
+		actor = audit.Actor{
+			ID:    myDetails.ID,
+			Name:  myDetails.Firstname + " " + myDetails.Surname,
+			Email: myDetails.Email,
+		}
+		a.actor = &actor
+	}
+
+	return actor
+}
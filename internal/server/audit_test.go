@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/audit"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockAuditSink struct {
+	events []audit.Event
+	err    error
+}
+
+func (m *mockAuditSink) Record(ctx context.Context, event audit.Event) error {
+	m.events = append(m.events, event)
+	return m.err
+}
+
+type mockAuditClient struct {
+	count int
+	err   error
+}
+
+func (m *mockAuditClient) MyDetails(ctx sirius.Context) (sirius.MyDetails, error) {
+	m.count += 1
+
+	return sirius.MyDetails{
+		ID:        1,
+		Firstname: "Anne",
+		Surname:   "Able",
+		Email:     "anne@example.com",
+	}, m.err
+}
+
+func serveWithAudit(client AuditClient, sink audit.Sink, r *http.Request, fn func(*http.Request)) {
+	handler := withAudit(client, sink)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fn(r)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+}
+
+func TestWithAudit(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockAuditClient{}
+	sink := &mockAuditSink{}
+
+	r, _ := http.NewRequest("POST", "/teams/edit/5", nil)
+
+	serveWithAudit(client, sink, r, func(r *http.Request) {
+		assert.True(auditing(r))
+
+		getAuditor(r).now = func() time.Time { return time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+		recordAudit(r, "edit-team", "team:5", sirius.Team{ID: 5, DisplayName: "A"}, sirius.Team{ID: 5, DisplayName: "B"}, nil)
+		recordAudit(r, "edit-team", "team:5", nil, nil, sirius.ValidationError{
+			Message: "Invalid",
+			Errors:  sirius.ValidationErrors{"name": {"isEmpty": "Enter a name"}},
+		})
+		recordAudit(r, "edit-team", "team:5", nil, nil, errors.New("oops"))
+	})
+
+	actor := audit.Actor{ID: 1, Name: "Anne Able", Email: "anne@example.com"}
+
+	assert.Equal(1, client.count)
+	assert.Equal([]audit.Event{
+		{
+			Time:    time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+			Action:  "edit-team",
+			Actor:   actor,
+			Target:  "team:5",
+			Before:  sirius.Team{ID: 5, DisplayName: "A"},
+			After:   sirius.Team{ID: 5, DisplayName: "B"},
+			Outcome: audit.OutcomeSuccess,
+		},
+		{
+			Time:             time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+			Action:           "edit-team",
+			Actor:            actor,
+			Target:           "team:5",
+			Outcome:          audit.OutcomeRejected,
+			Error:            "Invalid",
+			ValidationErrors: map[string]map[string]string{"name": {"isEmpty": "Enter a name"}},
+		},
+		{
+			Time:    time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+			Action:  "edit-team",
+			Actor:   actor,
+			Target:  "team:5",
+			Outcome: audit.OutcomeError,
+			Error:   "oops",
+		},
+	}, sink.events)
+}
+
+func TestWithAuditUnknownActor(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockAuditClient{err: errors.New("oops")}
+	sink := &mockAuditSink{}
+
+	ctx, logs := contextWithLogger()
+	r, _ := http.NewRequestWithContext(ctx, "POST", "/delete-user/5", nil)
+
+	serveWithAudit(client, sink, r, func(r *http.Request) {
+		recordAudit(r, "delete-user", "user:5", nil, nil, nil)
+	})
+
+	assert.Len(sink.events, 1)
+	assert.Equal(audit.Actor{}, sink.events[0].Actor)
+	assert.Contains(logs.String(), "could not identify user for audit event")
+}
+
+func TestWithAuditSinkError(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockAuditClient{}
+	sink := &mockAuditSink{err: errors.New("disk full")}
+
+	ctx, logs := contextWithLogger()
+	r, _ := http.NewRequestWithContext(ctx, "POST", "/delete-user/5", nil)
+
+	serveWithAudit(client, sink, r, func(r *http.Request) {
+		recordAudit(r, "delete-user", "user:5", nil, nil, nil)
+	})
+
+	assert.Contains(logs.String(), "could not record audit event")
+	assert.Contains(logs.String(), "disk full")
+}
+
+func TestWithAuditNoSink(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockAuditClient{}
+
+	r, _ := http.NewRequest("POST", "/delete-user/5", nil)
+
+	serveWithAudit(client, nil, r, func(r *http.Request) {
+		assert.False(auditing(r))
+		recordAudit(r, "delete-user", "user:5", nil, nil, nil)
+	})
+
+	assert.Equal(0, client.count)
+}
+
+func TestEditUserRecordsAudit(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockEditUserClient{}
+	client.user.data = sirius.AuthUser{ID: 123, Email: "a@example.com", Roles: []string{"Finance"}}
+	sink := &mockAuditSink{}
+
+	form := url.Values{
+		"email":        {"a@example.com"},
+		"organisation": {"OPG User"},
+		"roles":        {"Finance", "System Admin"},
+		"confirm":      {"confirm"},
+	}
+
+	r, _ := http.NewRequest("POST", "/edit-user/123", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	serveWithAudit(&mockAuditClient{}, sink, r, func(r *http.Request) {
+		err := editUser(client, &mockTemplate{})(client.requiredPermissions(), httptest.NewRecorder(), r)
+		assert.Nil(err)
+	})
+
+	assert.Len(sink.events, 1)
+	assert.Equal("edit-user", sink.events[0].Action)
+	assert.Equal("user:123", sink.events[0].Target)
+	assert.Equal(client.user.data, sink.events[0].Before)
+	assert.Equal([]string{"Finance", "System Admin"}, sink.events[0].After.(sirius.AuthUser).Roles)
+	assert.Equal(audit.OutcomeSuccess, sink.events[0].Outcome)
+}
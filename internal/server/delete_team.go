@@ -52,6 +52,7 @@ func deleteTeam(client DeleteTeamClient, tmpl Template) Handler {
 
 		if r.Method == http.MethodPost {
 			err := client.DeleteTeam(ctx, id)
+			recordAudit(r, "delete-team", fmt.Sprintf("team:%d", id), team, nil, err)
 
 			if _, ok := err.(sirius.ClientError); ok {
 				vars.Errors = sirius.ValidationErrors{
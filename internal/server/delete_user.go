@@ -54,6 +54,7 @@ func deleteUser(client DeleteUserClient, tmpl Template) Handler {
 
 		if r.Method == http.MethodPost {
 			err := client.DeleteUser(ctx, id)
+			recordAudit(r, "delete-user", fmt.Sprintf("user:%d", id), user, nil, err)
 
 			if e, ok := err.(sirius.ValidationError); ok {
 				vars.Errors = e.Errors
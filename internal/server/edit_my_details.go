@@ -1,6 +1,7 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
@@ -45,6 +46,7 @@ func editMyDetails(client EditMyDetailsClient, tmpl Template) Handler {
 		if r.Method == http.MethodPost {
 			vars.PhoneNumber = r.FormValue("phonenumber")
 			err := client.EditMyDetails(ctx, myDetails.ID, vars.PhoneNumber)
+			recordAudit(r, "edit-my-details", fmt.Sprintf("user:%d", myDetails.ID), myDetails.PhoneNumber, vars.PhoneNumber, err)
 
 			if e, ok := err.(sirius.ValidationError); ok {
 				vars.Errors = e.Errors
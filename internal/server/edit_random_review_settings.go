@@ -1,29 +1,38 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/randomreview"
 	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
 )
 
 type EditRandomReviewSettingsClient interface {
-	EditRandomReviewSettings(ctx sirius.Context, randomReviews sirius.EditRandomReview) error
 	RandomReviews(sirius.Context) (sirius.RandomReviews, error)
+	RandomReviewChangeClient
 }
 
 type editRandomReviewSettingsVars struct {
-	Path          string
-	XSRFToken     string
-	LayPercentage int
-	PaPercentage  int
-	ProPercentage int
-	ReviewCycle   int
-	Errors        sirius.ValidationErrors
-	Error         string
+	Path            string
+	XSRFToken       string
+	Current         sirius.RandomReviews
+	Settings        sirius.EditRandomReview
+	Reason          string
+	Population      randomreview.Population
+	CurrentEstimate randomreview.Estimate
+	NewEstimate     *randomreview.Estimate
+	Errors          sirius.ValidationErrors
 }
 
-func editRandomReviewSettings(client EditRandomReviewSettingsClient, tmpl Template) Handler {
+// editRandomReviewSettings shows all of the random review settings in a single
+// form. Changes are checked here before being proposed, so that they can be
+// approved by someone else. When the deputy population is known the number of
+// reviews the change would lead to can be previewed first.
+func editRandomReviewSettings(client EditRandomReviewSettingsClient, store RandomReviewStore, population randomreview.Population, tmpl Template) Handler {
 	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
 		if !perm.HasPermission("v1-random-review-settings", http.MethodPost) {
 			return StatusError(http.StatusForbidden)
@@ -31,43 +40,78 @@ func editRandomReviewSettings(client EditRandomReviewSettingsClient, tmpl Templa
 
 		ctx := getContext(r)
 
+		current, err := client.RandomReviews(ctx)
+		if err != nil {
+			return err
+		}
+
 		vars := editRandomReviewSettingsVars{
-			Path:      r.URL.Path,
-			XSRFToken: ctx.XSRFToken,
+			Path:            r.URL.Path,
+			XSRFToken:       ctx.XSRFToken,
+			Current:         current,
+			Population:      population,
+			CurrentEstimate: randomreview.EstimateReviews(population, current),
 		}
 
 		switch r.Method {
 		case http.MethodGet:
-			randomReviews, err := client.RandomReviews(ctx)
-			if err != nil {
-				return err
-			}
-
-			vars.LayPercentage = randomReviews.LayPercentage
-			vars.PaPercentage = randomReviews.PaPercentage
-			vars.ProPercentage = randomReviews.ProPercentage
-			vars.ReviewCycle = randomReviews.ReviewCycle
+			vars.Settings = randomreview.FillEdit(current, sirius.EditRandomReview{})
 
 			return tmpl.ExecuteTemplate(w, "page", vars)
 
 		case http.MethodPost:
-			randomReviewSettings, _ := client.RandomReviews(ctx)
+			vars.Settings = editRandomReviewFromForm(r)
+			vars.Reason = strings.TrimSpace(r.PostFormValue("reason"))
+
+			errs := validateRandomReviewEdit(vars.Settings, true)
+			if len(errs) == 0 && population.Known() {
+				estimate := randomreview.EstimateReviews(population, randomreview.EditedSettings(vars.Settings))
+				vars.NewEstimate = &estimate
+			}
+
+			if r.PostFormValue("action") == "preview" {
+				if len(errs) > 0 {
+					vars.Errors = errs
+					w.WriteHeader(http.StatusBadRequest)
+				}
+				return tmpl.ExecuteTemplate(w, "page", vars)
+			}
 
-			err := client.EditRandomReviewSettings(ctx, formValueOrExisting(r, randomReviewSettings))
+			if vars.Reason == "" {
+				errs["reason"] = map[string]string{"": "Enter a reason for the change"}
+			}
 
-			if verr, ok := err.(sirius.ValidationError); ok {
-				vars.LayPercentage = randomReviewSettings.LayPercentage
-				vars.PaPercentage = randomReviewSettings.PaPercentage
-				vars.ProPercentage = randomReviewSettings.ProPercentage
-				vars.ReviewCycle = randomReviewSettings.ReviewCycle
-				vars.Errors = verr.Errors
-				vars.Error = verr.Message
+			var changed sirius.EditRandomReview
+			if len(errs) == 0 {
+				changed = changedRandomReviewSettings(current, vars.Settings)
+				if changed == (sirius.EditRandomReview{}) {
+					errs["#"] = map[string]string{"": "Change at least one setting"}
+				}
+			}
 
+			if len(errs) > 0 {
+				vars.Errors = errs
 				w.WriteHeader(http.StatusBadRequest)
 				return tmpl.ExecuteTemplate(w, "page", vars)
-			} else if err != nil {
+			}
+
+			user, err := getRandomReviewUser(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			proposal, err := store.Propose(r.Context(), randomreview.Proposal{
+				CreatedAt:  time.Now().UTC(),
+				ProposedBy: user,
+				Before:     current,
+				Settings:   changed,
+				Reason:     vars.Reason,
+			})
+			recordAudit(r, "propose-random-review-settings", fmt.Sprintf("random-review-proposal:%d", proposal.ID), current, proposal, err)
+			if err != nil {
 				return err
 			}
+
 			return RedirectError("/random-reviews")
 
 		default:
@@ -76,31 +120,67 @@ func editRandomReviewSettings(client EditRandomReviewSettingsClient, tmpl Templa
 	}
 }
 
-func formValueOrExisting(r *http.Request, existing sirius.RandomReviews) sirius.EditRandomReview {
-	layPercentage := r.PostFormValue("layPercentage")
-	if layPercentage == "" {
-		layPercentage = strconv.Itoa(existing.LayPercentage)
+// editRandomReviewFromForm reads the settings that were submitted, leaving
+// those that were not blank.
+func editRandomReviewFromForm(r *http.Request) sirius.EditRandomReview {
+	return sirius.EditRandomReview{
+		LayPercentage: strings.TrimSpace(r.PostFormValue("layPercentage")),
+		PaPercentage:  strings.TrimSpace(r.PostFormValue("paPercentage")),
+		ProPercentage: strings.TrimSpace(r.PostFormValue("proPercentage")),
+		ReviewCycle:   strings.TrimSpace(r.PostFormValue("reviewCycle")),
 	}
+}
 
-	paPercentage := r.PostFormValue("paPercentage")
-	if paPercentage == "" {
-		paPercentage = strconv.Itoa(existing.PaPercentage)
+// validateRandomReviewEdit checks that percentages are whole numbers from 0 to
+// 100 and that the review cycle is a whole number of years. Blank settings are
+// only reported when required is set.
+func validateRandomReviewEdit(edit sirius.EditRandomReview, required bool) sirius.ValidationErrors {
+	errs := sirius.ValidationErrors{}
+
+	percentages := []struct{ field, value, cases string }{
+		{"layPercentage", edit.LayPercentage, "lay"},
+		{"paPercentage", edit.PaPercentage, "PA"},
+		{"proPercentage", edit.ProPercentage, "pro"},
 	}
 
-	proPercentage := r.PostFormValue("proPercentage")
-	if proPercentage == "" {
-		proPercentage = strconv.Itoa(existing.ProPercentage)
+	for _, p := range percentages {
+		if p.value == "" && !required {
+			continue
+		}
+
+		if n, err := strconv.Atoi(p.value); err != nil || n < 0 || n > 100 {
+			errs[p.field] = map[string]string{"": fmt.Sprintf("Enter a whole number between 0 and 100 for %s cases", p.cases)}
+		}
 	}
 
-	reviewCycle := r.PostFormValue("reviewCycle")
-	if reviewCycle == "" {
-		reviewCycle = strconv.Itoa(existing.ReviewCycle)
+	if edit.ReviewCycle != "" || required {
+		if n, err := strconv.Atoi(edit.ReviewCycle); err != nil || n < 1 {
+			errs["reviewCycle"] = map[string]string{"": "Enter the review cycle as a whole number of years, at least 1"}
+		}
 	}
 
-	return sirius.EditRandomReview{
-		LayPercentage: layPercentage,
-		PaPercentage:  paPercentage,
-		ProPercentage: proPercentage,
-		ReviewCycle:   reviewCycle,
+	return errs
+}
+
+// changedRandomReviewSettings returns only the settings in a complete, valid
+// edit that differ from the current ones, so that approving it later does not
+// undo other changes made in the meantime.
+func changedRandomReviewSettings(current sirius.RandomReviews, edit sirius.EditRandomReview) sirius.EditRandomReview {
+	edited := randomreview.EditedSettings(edit)
+
+	var changed sirius.EditRandomReview
+	if edited.LayPercentage != current.LayPercentage {
+		changed.LayPercentage = strconv.Itoa(edited.LayPercentage)
 	}
+	if edited.PaPercentage != current.PaPercentage {
+		changed.PaPercentage = strconv.Itoa(edited.PaPercentage)
+	}
+	if edited.ProPercentage != current.ProPercentage {
+		changed.ProPercentage = strconv.Itoa(edited.ProPercentage)
+	}
+	if edited.ReviewCycle != current.ReviewCycle {
+		changed.ReviewCycle = strconv.Itoa(edited.ReviewCycle)
+	}
+
+	return changed
 }
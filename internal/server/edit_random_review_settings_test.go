@@ -1,11 +1,13 @@
 package server
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/randomreview"
 	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
 	"github.com/stretchr/testify/assert"
 )
@@ -43,6 +45,10 @@ func (m *mockEditRandomReviewSettingsClient) EditRandomReviewSettings(ctx sirius
 	return m.err
 }
 
+func (m *mockEditRandomReviewSettingsClient) MyDetails(ctx sirius.Context) (sirius.MyDetails, error) {
+	return sirius.MyDetails{ID: 12, Firstname: "Anne", Surname: "Able", Email: "anne@example.com"}, nil
+}
+
 func (m *mockEditRandomReviewSettingsClient) requiredPermissions() sirius.PermissionSet {
 	return sirius.PermissionSet{"v1-random-review-settings": sirius.PermissionGroup{Permissions: []string{"post"}}}
 }
@@ -53,7 +59,7 @@ func TestNotAuthorised(t *testing.T) {
 
 	w := httptest.NewRecorder()
 	r, _ := http.NewRequest("GET", "/path", nil)
-	handler := editRandomReviewSettings(client, template)
+	handler := editRandomReviewSettings(client, &mockRandomReviewStore{}, randomreview.Population{}, template)
 
 	err := handler(sirius.PermissionSet{}, w, r)
 
@@ -65,7 +71,7 @@ func TestGetRandomReviewSettings(t *testing.T) {
 
 	data := sirius.RandomReviews{
 		LayPercentage: 10,
-		PaPercentage: 20,
+		PaPercentage:  20,
 		ReviewCycle:   1,
 	}
 
@@ -75,7 +81,7 @@ func TestGetRandomReviewSettings(t *testing.T) {
 	w := httptest.NewRecorder()
 	r, _ := http.NewRequest("GET", "/path", nil)
 
-	handler := editRandomReviewSettings(client, template)
+	handler := editRandomReviewSettings(client, &mockRandomReviewStore{}, randomreview.Population{}, template)
 	err := handler(client.requiredPermissions(), w, r)
 
 	assert.Nil(err)
@@ -87,10 +93,15 @@ func TestGetRandomReviewSettings(t *testing.T) {
 	assert.Equal(1, template.count)
 	assert.Equal("page", template.lastName)
 	assert.Equal(editRandomReviewSettingsVars{
-		Path:        "/path",
-		LayPercentage: 10,
-		PaPercentage: 20,
-		ReviewCycle:   1,
+		Path:    "/path",
+		Current: data,
+		Settings: sirius.EditRandomReview{
+			LayPercentage: "10",
+			PaPercentage:  "20",
+			ProPercentage: "0",
+			ReviewCycle:   "1",
+		},
+		CurrentEstimate: randomreview.Estimate{ReviewCycle: 1},
 	}, template.lastVars)
 }
 
@@ -99,73 +110,201 @@ func TestPostRandomReviewSettings(t *testing.T) {
 
 	data := sirius.RandomReviews{
 		LayPercentage: 10,
-		PaPercentage: 20,
+		PaPercentage:  20,
 		ReviewCycle:   1,
 	}
 
 	client := &mockEditRandomReviewSettingsClient{data: data}
+	store := &mockRandomReviewStore{}
 	template := &mockTemplate{}
 
 	w := httptest.NewRecorder()
-	r, _ := http.NewRequest("POST", "/path", strings.NewReader("layReviewCycle=1"))
+	r, _ := http.NewRequest("POST", "/path", strings.NewReader("layPercentage=15&paPercentage=20&proPercentage=0&reviewCycle=01&reason=Audit+recommendation"))
 	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
-	handler := editRandomReviewSettings(client, template)
+	handler := editRandomReviewSettings(client, store, randomreview.Population{}, template)
 
 	err := handler(client.requiredPermissions(), w, r)
 	assert.Equal(RedirectError("/random-reviews"), err)
 
-	resp := w.Result()
-	assert.Equal(http.StatusOK, resp.StatusCode)
 	assert.Equal(getContext(r), client.lastCtx)
-
 	assert.Equal(0, template.count)
-	assert.Equal(1, client.data.ReviewCycle)
-	assert.Equal(10, client.data.LayPercentage)
-	assert.Equal(20, client.data.PaPercentage)
+	assert.Equal(0, client.saveCount)
+
+	assert.Len(store.proposals, 1)
+	proposal := store.proposals[0]
+	assert.False(proposal.CreatedAt.IsZero())
+	assert.Equal(randomreview.User{ID: 12, Name: "Anne Able", Email: "anne@example.com"}, proposal.ProposedBy)
+	assert.Equal(data, proposal.Before)
+	assert.Equal(sirius.EditRandomReview{LayPercentage: "15"}, proposal.Settings)
+	assert.Equal("Audit recommendation", proposal.Reason)
+	assert.Equal(randomreview.StatusPending, proposal.Status)
 }
 
-func TestPostRandomReviewSettingsValidationError(t *testing.T) {
+func TestPostRandomReviewSettingsValidation(t *testing.T) {
+	testCases := map[string]struct {
+		form   string
+		errors sirius.ValidationErrors
+	}{
+		"no reason": {
+			form:   "layPercentage=15&paPercentage=20&proPercentage=0&reviewCycle=1&reason=+",
+			errors: sirius.ValidationErrors{"reason": {"": "Enter a reason for the change"}},
+		},
+		"percentage too high": {
+			form:   "layPercentage=200&paPercentage=20&proPercentage=0&reviewCycle=1&reason=x",
+			errors: sirius.ValidationErrors{"layPercentage": {"": "Enter a whole number between 0 and 100 for lay cases"}},
+		},
+		"negative percentage": {
+			form:   "layPercentage=10&paPercentage=-1&proPercentage=0&reviewCycle=1&reason=x",
+			errors: sirius.ValidationErrors{"paPercentage": {"": "Enter a whole number between 0 and 100 for PA cases"}},
+		},
+		"not a number": {
+			form:   "layPercentage=10&paPercentage=20&proPercentage=1.5&reviewCycle=1&reason=x",
+			errors: sirius.ValidationErrors{"proPercentage": {"": "Enter a whole number between 0 and 100 for pro cases"}},
+		},
+		"zero review cycle": {
+			form:   "layPercentage=10&paPercentage=20&proPercentage=0&reviewCycle=0&reason=x",
+			errors: sirius.ValidationErrors{"reviewCycle": {"": "Enter the review cycle as a whole number of years, at least 1"}},
+		},
+		"blank": {
+			form: "reason=x",
+			errors: sirius.ValidationErrors{
+				"layPercentage": {"": "Enter a whole number between 0 and 100 for lay cases"},
+				"paPercentage":  {"": "Enter a whole number between 0 and 100 for PA cases"},
+				"proPercentage": {"": "Enter a whole number between 0 and 100 for pro cases"},
+				"reviewCycle":   {"": "Enter the review cycle as a whole number of years, at least 1"},
+			},
+		},
+		"unchanged": {
+			form:   "layPercentage=10&paPercentage=20&proPercentage=0&reviewCycle=1&reason=x",
+			errors: sirius.ValidationErrors{"#": {"": "Change at least one setting"}},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			data := sirius.RandomReviews{
+				LayPercentage: 10,
+				PaPercentage:  20,
+				ReviewCycle:   1,
+			}
+
+			client := &mockEditRandomReviewSettingsClient{data: data}
+			store := &mockRandomReviewStore{}
+			template := &mockTemplate{}
 
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest("POST", "/path", strings.NewReader(tc.form))
+			r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+			err := editRandomReviewSettings(client, store, randomreview.Population{}, template)(client.requiredPermissions(), w, r)
+			assert.Nil(err)
+
+			assert.Equal(http.StatusBadRequest, w.Result().StatusCode)
+			assert.Equal(1, template.count)
+			assert.Equal("page", template.lastName)
+
+			vars := template.lastVars.(editRandomReviewSettingsVars)
+			assert.Equal(tc.errors, vars.Errors)
+			assert.Equal(data, vars.Current)
+			assert.Equal(editRandomReviewFromForm(r), vars.Settings)
+
+			assert.Equal(0, client.saveCount)
+			assert.Empty(store.proposals)
+		})
+	}
+}
+
+func TestPostRandomReviewSettingsPreview(t *testing.T) {
 	assert := assert.New(t)
 
 	data := sirius.RandomReviews{
 		LayPercentage: 10,
-		PaPercentage: 20,
+		PaPercentage:  20,
 		ReviewCycle:   1,
 	}
-
-	errors := sirius.ValidationErrors{
-		"x": {
-			"y": "z",
-		},
-	}
+	population := randomreview.Population{Lay: 1000, PA: 100, Pro: 10}
 
 	client := &mockEditRandomReviewSettingsClient{data: data}
-	client.err = sirius.ValidationError{
-		Errors: errors,
-	}
-
+	store := &mockRandomReviewStore{}
 	template := &mockTemplate{}
 
 	w := httptest.NewRecorder()
-	r, _ := http.NewRequest("POST", "/path", strings.NewReader("reviewCycle=-1"))
+	r, _ := http.NewRequest("POST", "/path", strings.NewReader("layPercentage=15&paPercentage=20&proPercentage=50&reviewCycle=2&action=preview"))
 	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
-	handler := editRandomReviewSettings(client, template)
-
-	err := handler(client.requiredPermissions(), w, r)
+	err := editRandomReviewSettings(client, store, population, template)(client.requiredPermissions(), w, r)
 	assert.Nil(err)
 
-	resp := w.Result()
-	assert.Equal(http.StatusBadRequest, resp.StatusCode)
-	assert.Equal(1, template.count)
-	assert.Equal("page", template.lastName)
+	assert.Equal(http.StatusOK, w.Result().StatusCode)
 	assert.Equal(editRandomReviewSettingsVars{
-		Path:        "/path",
-		LayPercentage: 10,
-		PaPercentage: 20,
-		ReviewCycle:   1,
-		Errors:      errors,
+		Path:    "/path",
+		Current: data,
+		Settings: sirius.EditRandomReview{
+			LayPercentage: "15",
+			PaPercentage:  "20",
+			ProPercentage: "50",
+			ReviewCycle:   "2",
+		},
+		Population:      population,
+		CurrentEstimate: randomreview.Estimate{Lay: 100, PA: 20, Total: 120, ReviewCycle: 1},
+		NewEstimate:     &randomreview.Estimate{Lay: 150, PA: 20, Pro: 5, Total: 175, ReviewCycle: 2},
 	}, template.lastVars)
+	assert.Empty(store.proposals)
+}
+
+func TestPostRandomReviewSettingsPreviewInvalid(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockEditRandomReviewSettingsClient{}
+	store := &mockRandomReviewStore{}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/path", strings.NewReader("layPercentage=150&paPercentage=20&proPercentage=50&reviewCycle=1&action=preview"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := editRandomReviewSettings(client, store, randomreview.Population{Lay: 1000}, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(http.StatusBadRequest, w.Result().StatusCode)
+	vars := template.lastVars.(editRandomReviewSettingsVars)
+	assert.Contains(vars.Errors, "layPercentage")
+	assert.Nil(vars.NewEstimate)
+	assert.Empty(store.proposals)
+}
+
+func TestPostRandomReviewSettingsStoreError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedErr := errors.New("err")
+
+	client := &mockEditRandomReviewSettingsClient{}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/path", strings.NewReader("layPercentage=15&paPercentage=0&proPercentage=0&reviewCycle=1&reason=Typo"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := editRandomReviewSettings(client, &mockRandomReviewStore{err: expectedErr}, randomreview.Population{}, template)(client.requiredPermissions(), w, r)
+	assert.Equal(expectedErr, err)
+	assert.Equal(0, template.count)
+}
+
+func TestRandomReviewSettingsClientError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedErr := errors.New("err")
+
+	client := &mockEditRandomReviewSettingsClient{err: expectedErr}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/path", nil)
+
+	err := editRandomReviewSettings(client, &mockRandomReviewStore{}, randomreview.Population{}, template)(client.requiredPermissions(), w, r)
+	assert.Equal(expectedErr, err)
+	assert.Equal(0, template.count)
 }
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -80,6 +81,7 @@ func editTeam(client EditTeamClient, tmpl Template) Handler {
 
 			// Attempt to save
 			err := client.EditTeam(ctx, vars.Team)
+			recordAudit(r, "edit-team", fmt.Sprintf("team:%d", id), team, vars.Team, err)
 
 			if e, ok := err.(sirius.ValidationError); ok {
 				vars.Errors = e.Errors
@@ -1,7 +1,9 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -14,16 +16,35 @@ type EditUserClient interface {
 	Roles(sirius.Context) ([]string, error)
 }
 
+// privilegedRoles are highlighted when they are granted to a user, so that
+// they are not given out by accident.
+var privilegedRoles = []string{"System Admin"}
+
 type editUserVars struct {
 	Path        string
 	XSRFToken   string
 	Roles       []string
 	HiddenRoles []string
 	User        sirius.AuthUser
+	Confirm     bool
+	Changes     editUserChanges
 	Success     bool
 	Errors      sirius.ValidationErrors
 }
 
+type editUserChanges struct {
+	AddedRoles       []string
+	RemovedRoles     []string
+	PrivilegedRoles  []string
+	Organisation     bool
+	FromOrganisation string
+	Suspended        bool
+}
+
+func (c editUserChanges) Any() bool {
+	return len(c.AddedRoles) > 0 || len(c.RemovedRoles) > 0 || c.Organisation || c.Suspended
+}
+
 func editUser(client EditUserClient, tmpl Template) Handler {
 	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
 		if !perm.HasPermission("v1-users", http.MethodPut) {
@@ -70,10 +91,27 @@ func editUser(client EditUserClient, tmpl Template) Handler {
 				Roles:        r.PostForm["roles"],
 				Suspended:    r.PostFormValue("suspended") == "Yes",
 			}
-			err := client.EditUser(ctx, vars.User)
-
 			vars.HiddenRoles = getUserHiddenRoles(r.PostForm["roles"], vars.Roles)
 
+			if r.PostFormValue("change") != "" {
+				return tmpl.ExecuteTemplate(w, "page", vars)
+			}
+
+			current, err := client.User(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			if r.PostFormValue("confirm") == "" {
+				vars.Confirm = true
+				vars.Changes = getEditUserChanges(current, vars.User)
+
+				return tmpl.ExecuteTemplate(w, "page", vars)
+			}
+
+			err = client.EditUser(ctx, vars.User)
+			recordAudit(r, "edit-user", fmt.Sprintf("user:%d", id), current, vars.User, err)
+
 			if e, ok := err.(sirius.ValidationError); ok {
 				vars.Errors = e.Errors
 
@@ -94,6 +132,32 @@ func editUser(client EditUserClient, tmpl Template) Handler {
 	}
 }
 
+func getEditUserChanges(current, updated sirius.AuthUser) editUserChanges {
+	changes := editUserChanges{
+		Organisation:     current.Organisation != updated.Organisation,
+		FromOrganisation: current.Organisation,
+		Suspended:        current.Suspended != updated.Suspended,
+	}
+
+	for _, role := range updated.Roles {
+		if !slices.Contains(current.Roles, role) {
+			changes.AddedRoles = append(changes.AddedRoles, role)
+
+			if slices.Contains(privilegedRoles, role) {
+				changes.PrivilegedRoles = append(changes.PrivilegedRoles, role)
+			}
+		}
+	}
+
+	for _, role := range current.Roles {
+		if !slices.Contains(updated.Roles, role) {
+			changes.RemovedRoles = append(changes.RemovedRoles, role)
+		}
+	}
+
+	return changes
+}
+
 func getUserHiddenRoles(userRoles []string, visibleRoles []string) []string {
 	hiddenRoles := []string{}
 
@@ -155,7 +155,7 @@ func TestPostEditUser(t *testing.T) {
 	template := &mockTemplate{}
 
 	w := httptest.NewRecorder()
-	r, _ := http.NewRequest("POST", "/edit-user/123", strings.NewReader("email=a&firstname=b&surname=c&organisation=d&roles=System+Admin&roles=Manager&suspended=No"))
+	r, _ := http.NewRequest("POST", "/edit-user/123", strings.NewReader("email=a&firstname=b&surname=c&organisation=d&roles=System+Admin&roles=Manager&suspended=No&confirm=confirm"))
 	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
 	err := editUser(client, template)(client.requiredPermissions(), w, r)
@@ -176,7 +176,7 @@ func TestPostEditUser(t *testing.T) {
 		Suspended:    false,
 	}, client.editUser.lastUser)
 
-	assert.Equal(0, client.user.count)
+	assert.Equal(1, client.user.count)
 
 	assert.Equal(1, template.count)
 	assert.Equal("page", template.lastName)
@@ -208,7 +208,7 @@ func TestPostEditUserValidationError(t *testing.T) {
 	template := &mockTemplate{}
 
 	w := httptest.NewRecorder()
-	r, _ := http.NewRequest("POST", "/edit-user/123", strings.NewReader("email=a&firstname=b&surname=c&organisation=d&roles=System+Admin&suspended=No"))
+	r, _ := http.NewRequest("POST", "/edit-user/123", strings.NewReader("email=a&firstname=b&surname=c&organisation=d&roles=System+Admin&suspended=No&confirm=confirm"))
 	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
 	err := editUser(client, template)(client.requiredPermissions(), w, r)
@@ -216,7 +216,7 @@ func TestPostEditUserValidationError(t *testing.T) {
 
 	assert.Equal(1, client.roles.count)
 	assert.Equal(1, client.editUser.count)
-	assert.Equal(0, client.user.count)
+	assert.Equal(1, client.user.count)
 
 	assert.Equal(1, template.count)
 	assert.Equal("page", template.lastName)
@@ -252,7 +252,7 @@ func TestPostEditUserWithHiddenRole(t *testing.T) {
 	template := &mockTemplate{}
 
 	w := httptest.NewRecorder()
-	r, _ := http.NewRequest("POST", "/edit-user/123", strings.NewReader("roles=Manager&roles=private-hidden"))
+	r, _ := http.NewRequest("POST", "/edit-user/123", strings.NewReader("roles=Manager&roles=private-hidden&confirm=confirm"))
 	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
 	err := editUser(client, template)(client.requiredPermissions(), w, r)
@@ -284,14 +284,15 @@ func TestPostEditUserOtherError(t *testing.T) {
 	template := &mockTemplate{}
 
 	w := httptest.NewRecorder()
-	r, _ := http.NewRequest("POST", "/edit-user/123", nil)
+	r, _ := http.NewRequest("POST", "/edit-user/123", strings.NewReader("confirm=confirm"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
 	err := editUser(client, template)(client.requiredPermissions(), w, r)
 	assert.Equal(expectedErr, err)
 
 	assert.Equal(1, client.roles.count)
 	assert.Equal(1, client.editUser.count)
-	assert.Equal(0, client.user.count)
+	assert.Equal(1, client.user.count)
 	assert.Equal(0, template.count)
 }
 
@@ -314,3 +315,104 @@ func TestPostEditUserRolesError(t *testing.T) {
 	assert.Equal(0, client.user.count)
 	assert.Equal(0, template.count)
 }
+
+func TestPostEditUserConfirmation(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockEditUserClient{}
+	client.user.data = sirius.AuthUser{
+		ID:           123,
+		Organisation: "COP User",
+		Roles:        []string{"Manager", "Finance"},
+	}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/edit-user/123", strings.NewReader("email=a&organisation=OPG+User&roles=System+Admin&roles=Manager&suspended=Yes"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := editUser(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(1, client.user.count)
+	assert.Equal(123, client.user.lastID)
+	assert.Equal(0, client.editUser.count)
+
+	assert.Equal(1, template.count)
+	assert.Equal(editUserVars{
+		Path:  "/edit-user/123",
+		Roles: []string{"System Admin", "Manager"},
+		User: sirius.AuthUser{
+			ID:           123,
+			Email:        "a",
+			Organisation: "OPG User",
+			Roles:        []string{"System Admin", "Manager"},
+			Suspended:    true,
+		},
+		Confirm: true,
+		Changes: editUserChanges{
+			AddedRoles:       []string{"System Admin"},
+			RemovedRoles:     []string{"Finance"},
+			PrivilegedRoles:  []string{"System Admin"},
+			Organisation:     true,
+			FromOrganisation: "COP User",
+			Suspended:        true,
+		},
+	}, template.lastVars)
+	assert.True(template.lastVars.(editUserVars).Changes.Any())
+}
+
+func TestPostEditUserConfirmationUserError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedErr := errors.New("oops")
+	client := &mockEditUserClient{}
+	client.user.err = expectedErr
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/edit-user/123", strings.NewReader("email=a"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := editUser(client, template)(client.requiredPermissions(), w, r)
+	assert.Equal(expectedErr, err)
+
+	assert.Equal(0, client.editUser.count)
+	assert.Equal(0, template.count)
+}
+
+func TestPostEditUserChange(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockEditUserClient{}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/edit-user/123", strings.NewReader("email=a&organisation=OPG+User&roles=Manager&change=change"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := editUser(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(0, client.user.count)
+	assert.Equal(0, client.editUser.count)
+
+	assert.Equal(editUserVars{
+		Path:  "/edit-user/123",
+		Roles: []string{"System Admin", "Manager"},
+		User: sirius.AuthUser{
+			ID:           123,
+			Email:        "a",
+			Organisation: "OPG User",
+			Roles:        []string{"Manager"},
+		},
+	}, template.lastVars)
+}
+
+func TestGetEditUserChangesNone(t *testing.T) {
+	user := sirius.AuthUser{Organisation: "OPG User", Roles: []string{"Manager"}}
+
+	changes := getEditUserChanges(user, user)
+	assert.False(t, changes.Any())
+	assert.Equal(t, editUserChanges{FromOrganisation: "OPG User"}, changes)
+}
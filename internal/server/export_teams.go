@@ -0,0 +1,104 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+type exportFormat string
+
+const (
+	exportFormatCSV  exportFormat = "csv"
+	exportFormatJSON exportFormat = "json"
+)
+
+type ExportTeamsClient interface {
+	Teams(sirius.Context) ([]sirius.Team, error)
+}
+
+type exportedTeam struct {
+	ID          int                  `json:"id"`
+	Name        string               `json:"name"`
+	Type        string               `json:"type"`
+	Email       string               `json:"email"`
+	PhoneNumber string               `json:"phoneNumber"`
+	Members     []exportedTeamMember `json:"members"`
+}
+
+type exportedTeamMember struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func exportTeams(client ExportTeamsClient, format exportFormat) Handler {
+	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		if !perm.HasPermission("v1-teams", http.MethodPut) {
+			return StatusError(http.StatusForbidden)
+		}
+
+		if r.Method != http.MethodGet {
+			return StatusError(http.StatusMethodNotAllowed)
+		}
+
+		ctx := getContext(r)
+
+		teams, err := client.Teams(ctx)
+		if err != nil {
+			return err
+		}
+
+		roster := make([]exportedTeam, len(teams))
+
+		for i, team := range teams {
+			roster[i] = exportedTeam{
+				ID:          team.ID,
+				Name:        team.DisplayName,
+				Type:        team.TypeLabel,
+				Email:       team.Email,
+				PhoneNumber: team.PhoneNumber,
+				Members:     []exportedTeamMember{},
+			}
+
+			for _, m := range team.Members {
+				roster[i].Members = append(roster[i].Members, exportedTeamMember{
+					ID:    m.ID,
+					Name:  m.DisplayName,
+					Email: m.Email,
+				})
+			}
+		}
+
+		if format == exportFormatJSON {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Disposition", `attachment; filename="teams.json"`)
+
+			return json.NewEncoder(w).Encode(roster)
+		}
+
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="teams.csv"`)
+
+		out := csv.NewWriter(w)
+		_ = out.Write([]string{"Team ID", "Team name", "Team type", "Team email", "Team phone number", "Member ID", "Member name", "Member email"})
+
+		for _, team := range roster {
+			row := []string{strconv.Itoa(team.ID), team.Name, team.Type, team.Email, team.PhoneNumber}
+
+			if len(team.Members) == 0 {
+				_ = out.Write(spreadsheetSafe(append(row, "", "", "")))
+			}
+
+			for _, m := range team.Members {
+				_ = out.Write(spreadsheetSafe(append(row[:5:5], strconv.Itoa(m.ID), m.Name, m.Email)))
+			}
+		}
+
+		out.Flush()
+		return out.Error()
+	}
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockExportTeamsClient struct {
+	count int
+	data  []sirius.Team
+	err   error
+}
+
+func (m *mockExportTeamsClient) Teams(ctx sirius.Context) ([]sirius.Team, error) {
+	m.count += 1
+
+	return m.data, m.err
+}
+
+func (m *mockExportTeamsClient) requiredPermissions() sirius.PermissionSet {
+	return sirius.PermissionSet{"v1-teams": sirius.PermissionGroup{Permissions: []string{"put"}}}
+}
+
+func newMockExportTeamsClient() *mockExportTeamsClient {
+	return &mockExportTeamsClient{
+		data: []sirius.Team{
+			{
+				ID:          1,
+				DisplayName: "Cool team",
+				TypeLabel:   "Supervision — Allocations",
+				Email:       "cool@example.com",
+				PhoneNumber: "0123",
+				Members: []sirius.TeamMember{
+					{ID: 5, DisplayName: "Anne Able", Email: "a@example.com"},
+					{ID: 6, DisplayName: "Bob Bell", Email: "b@example.com"},
+				},
+			},
+			{ID: 2, DisplayName: "Empty team", TypeLabel: "LPA"},
+		},
+	}
+}
+
+func TestExportTeamsCSV(t *testing.T) {
+	assert := assert.New(t)
+
+	client := newMockExportTeamsClient()
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/teams/export.csv", nil)
+
+	err := exportTeams(client, exportFormatCSV)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(1, client.count)
+
+	resp := w.Result()
+	assert.Equal("text/csv", resp.Header.Get("Content-Type"))
+	assert.Equal(`attachment; filename="teams.csv"`, resp.Header.Get("Content-Disposition"))
+	assert.Equal(`Team ID,Team name,Team type,Team email,Team phone number,Member ID,Member name,Member email
+1,Cool team,Supervision — Allocations,cool@example.com,0123,5,Anne Able,a@example.com
+1,Cool team,Supervision — Allocations,cool@example.com,0123,6,Bob Bell,b@example.com
+2,Empty team,LPA,,,,,
+`, w.Body.String())
+}
+
+func TestExportTeamsCSVEscapesFormulas(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockExportTeamsClient{
+		data: []sirius.Team{{
+			ID:          1,
+			DisplayName: "+Team",
+			TypeLabel:   "LPA",
+			PhoneNumber: "+44 123",
+			Members:     []sirius.TeamMember{{ID: 5, DisplayName: "=1+1", Email: "a@example.com"}},
+		}},
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/teams/export.csv", nil)
+
+	err := exportTeams(client, exportFormatCSV)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(`Team ID,Team name,Team type,Team email,Team phone number,Member ID,Member name,Member email
+1,'+Team,LPA,,'+44 123,5,'=1+1,a@example.com
+`, w.Body.String())
+}
+
+func TestExportTeamsJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	client := newMockExportTeamsClient()
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/teams/export.json", nil)
+
+	err := exportTeams(client, exportFormatJSON)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	resp := w.Result()
+	assert.Equal("application/json", resp.Header.Get("Content-Type"))
+	assert.JSONEq(`[
+		{"id":1,"name":"Cool team","type":"Supervision — Allocations","email":"cool@example.com","phoneNumber":"0123","members":[
+			{"id":5,"name":"Anne Able","email":"a@example.com"},
+			{"id":6,"name":"Bob Bell","email":"b@example.com"}
+		]},
+		{"id":2,"name":"Empty team","type":"LPA","email":"","phoneNumber":"","members":[]}
+	]`, w.Body.String())
+}
+
+func TestExportTeamsNoPermission(t *testing.T) {
+	assert := assert.New(t)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/teams/export.csv", nil)
+
+	err := exportTeams(nil, exportFormatCSV)(sirius.PermissionSet{}, w, r)
+	assert.Equal(StatusError(http.StatusForbidden), err)
+}
+
+func TestExportTeamsBadMethod(t *testing.T) {
+	assert := assert.New(t)
+
+	client := newMockExportTeamsClient()
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/teams/export.csv", nil)
+
+	err := exportTeams(client, exportFormatCSV)(client.requiredPermissions(), w, r)
+	assert.Equal(StatusError(http.StatusMethodNotAllowed), err)
+	assert.Equal(0, client.count)
+}
+
+func TestExportTeamsError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedError := errors.New("oops")
+
+	client := &mockExportTeamsClient{err: expectedError}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/teams/export.csv", nil)
+
+	err := exportTeams(client, exportFormatCSV)(client.requiredPermissions(), w, r)
+	assert.Equal(expectedError, err)
+	assert.Equal(1, client.count)
+	assert.Equal("", w.Body.String())
+}
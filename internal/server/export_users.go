@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+type ExportUsersClient interface {
+	SearchUsers(sirius.Context, string) ([]sirius.User, error)
+}
+
+func exportUsers(client ExportUsersClient) Handler {
+	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		if !perm.HasPermission("v1-users", http.MethodPut) {
+			return StatusError(http.StatusForbidden)
+		}
+
+		if r.Method != http.MethodGet {
+			return StatusError(http.StatusMethodNotAllowed)
+		}
+
+		search := r.FormValue("search")
+
+		team, _ := strconv.Atoi(r.FormValue("team"))
+
+		users, err := client.SearchUsers(getContext(r), search)
+		if _, ok := err.(sirius.ClientError); ok {
+			return RedirectError("/users?search=" + url.QueryEscape(search))
+		} else if err != nil {
+			return err
+		}
+
+		users = filterUsersByTeam(users, team)
+
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="users.csv"`)
+
+		out := csv.NewWriter(w)
+		_ = out.Write([]string{"ID", "Name", "Email", "Status", "Team"})
+
+		for _, user := range users {
+			_ = out.Write(spreadsheetSafe([]string{strconv.Itoa(user.ID), user.DisplayName, user.Email, user.Status.String(), strings.Join(user.TeamNames(), ", ")}))
+		}
+
+		out.Flush()
+		return out.Error()
+	}
+}
+
+// spreadsheetSafe stops cells being run as formulas when a CSV file is opened
+// in a spreadsheet, by prefixing those that would be with a quote.
+func spreadsheetSafe(row []string) []string {
+	for i, cell := range row {
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			row[i] = "'" + cell
+		}
+	}
+
+	return row
+}
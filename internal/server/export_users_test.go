@@ -0,0 +1,121 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportUsers(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockListUsersClient{}
+	client.data = []sirius.User{
+		{ID: 29, DisplayName: "Darian Kramer", Email: "darian.kramer@example.com", Status: "Active", Team: "Cool Team", Teams: []sirius.UserTeam{{ID: 1, DisplayName: "Cool Team"}, {ID: 2, DisplayName: "Cover Team"}}},
+		{ID: 30, DisplayName: "Milo Nihei", Email: "milo.nihei@example.com", Status: "Suspended"},
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/users/export.csv?search=milo", nil)
+
+	err := exportUsers(client)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(1, client.count)
+	assert.Equal("milo", client.lastSearch)
+
+	resp := w.Result()
+	assert.Equal("text/csv", resp.Header.Get("Content-Type"))
+	assert.Equal(`attachment; filename="users.csv"`, resp.Header.Get("Content-Disposition"))
+	assert.Equal(`ID,Name,Email,Status,Team
+29,Darian Kramer,darian.kramer@example.com,Active,"Cool Team, Cover Team"
+30,Milo Nihei,milo.nihei@example.com,Suspended,
+`, w.Body.String())
+}
+
+func TestExportUsersEscapesFormulas(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockListUsersClient{}
+	client.data = []sirius.User{
+		{ID: 29, DisplayName: "=HYPERLINK(\"http://example.com\")", Email: "@SUM(A1)", Status: "Active", Teams: []sirius.UserTeam{{ID: 1, DisplayName: "-Team"}}},
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/users/export.csv", nil)
+
+	err := exportUsers(client)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(`ID,Name,Email,Status,Team
+29,"'=HYPERLINK(""http://example.com"")",'@SUM(A1),Active,'-Team
+`, w.Body.String())
+}
+
+func TestSpreadsheetSafe(t *testing.T) {
+	assert.Equal(t,
+		[]string{"", "1", "Anne", "'=1+1", "'+44", "'-2", "'@A1", "'\tx", "'\rx", "a=b"},
+		spreadsheetSafe([]string{"", "1", "Anne", "=1+1", "+44", "-2", "@A1", "\tx", "\rx", "a=b"}))
+}
+
+func TestExportUsersNoPermission(t *testing.T) {
+	assert := assert.New(t)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/users/export.csv", nil)
+
+	err := exportUsers(nil)(sirius.PermissionSet{}, w, r)
+	assert.Equal(StatusError(http.StatusForbidden), err)
+}
+
+func TestExportUsersSearchTooShort(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockListUsersClient{}
+	client.err = sirius.ClientError("Search term must be at least three characters")
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/users/export.csv?search=a", nil)
+
+	err := exportUsers(client)(client.requiredPermissions(), w, r)
+	assert.Equal(RedirectError("/users?search=a"), err)
+}
+
+func TestExportUsersError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedError := errors.New("oops")
+
+	client := &mockListUsersClient{}
+	client.err = expectedError
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/users/export.csv?search=milo", nil)
+
+	err := exportUsers(client)(client.requiredPermissions(), w, r)
+	assert.Equal(expectedError, err)
+}
+
+func TestExportUsersFilteredByTeam(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockListUsersClient{}
+	client.data = []sirius.User{
+		{ID: 29, DisplayName: "Darian Kramer", Email: "darian.kramer@example.com", Status: "Active", Teams: []sirius.UserTeam{{ID: 1, DisplayName: "Cool Team"}}},
+		{ID: 30, DisplayName: "Milo Nihei", Email: "milo.nihei@example.com", Status: "Suspended"},
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/users/export.csv?search=milo&team=1", nil)
+
+	err := exportUsers(client)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(`ID,Name,Email,Status,Team
+29,Darian Kramer,darian.kramer@example.com,Active,Cool Team
+`, w.Body.String())
+}
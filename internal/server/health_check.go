@@ -1,9 +1,98 @@
 package server
 
 import (
+	"context"
+	"fmt"
+	"html/template"
+	"io/fs"
 	"net/http"
+	"time"
+
+	"github.com/ministryofjustice/opg-go-common/telemetry"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
 )
 
+const siriusProbeTimeout = 2 * time.Second
+
+type HealthCheckClient interface {
+	Ping(sirius.Context) error
+}
+
+type readinessCheck struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type readinessResponse struct {
+	Status string                    `json:"status"`
+	Checks map[string]readinessCheck `json:"checks"`
+}
+
 func healthCheck() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
 }
+
+// healthCheckReady reports whether the service is able to handle requests,
+// unlike healthCheck which only shows that the process is running.
+func healthCheckReady(client HealthCheckClient, templates map[string]*template.Template, webFS fs.FS, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := readinessResponse{
+			Status: "ok",
+			Checks: map[string]readinessCheck{
+				"templates": checkTemplates(templates),
+				"static":    checkStaticDir(webFS),
+				"sirius":    checkSirius(r.Context(), client, timeout),
+			},
+		}
+
+		code := http.StatusOK
+		for _, check := range resp.Checks {
+			if check.Status != "ok" {
+				resp.Status = "fail"
+				code = http.StatusServiceUnavailable
+			}
+		}
+
+		if err := writeJSON(w, code, resp); err != nil {
+			telemetry.LoggerFromContext(r.Context()).Error("could not write readiness response")
+		}
+	})
+}
+
+func readinessResult(err error) readinessCheck {
+	if err != nil {
+		return readinessCheck{Status: "fail", Error: err.Error()}
+	}
+
+	return readinessCheck{Status: "ok"}
+}
+
+func checkTemplates(templates map[string]*template.Template) readinessCheck {
+	if len(templates) == 0 {
+		return readinessResult(fmt.Errorf("no templates loaded"))
+	}
+
+	for name, tmpl := range templates {
+		if tmpl == nil || tmpl.Lookup("page") == nil {
+			return readinessResult(fmt.Errorf("template %s does not define page", name))
+		}
+	}
+
+	return readinessResult(nil)
+}
+
+func checkStaticDir(webFS fs.FS) readinessCheck {
+	info, err := fs.Stat(webFS, "static")
+	if err == nil && !info.IsDir() {
+		err = fmt.Errorf("static is not a directory")
+	}
+
+	return readinessResult(err)
+}
+
+func checkSirius(ctx context.Context, client HealthCheckClient, timeout time.Duration) readinessCheck {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return readinessResult(client.Ping(sirius.Context{Context: ctx}))
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockHealthCheckClient struct {
+	count   int
+	lastCtx sirius.Context
+	err     error
+}
+
+func (m *mockHealthCheckClient) Ping(ctx sirius.Context) error {
+	m.count += 1
+	m.lastCtx = ctx
+
+	return m.err
+}
+
+func readyTemplates() map[string]*template.Template {
+	return map[string]*template.Template{
+		"users.gotmpl": template.Must(template.New("").Parse(`{{ define "page" }}{{ end }}`)),
+	}
+}
+
+func readyWebFS() fs.FS {
+	return fstest.MapFS{
+		"static/stylesheets/all.css": &fstest.MapFile{},
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/health-check", nil)
+
+	healthCheck().ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+}
+
+func TestHealthCheckReady(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockHealthCheckClient{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/health-check/ready", nil)
+
+	healthCheckReady(client, readyTemplates(), readyWebFS(), time.Second).ServeHTTP(w, r)
+
+	assert.Equal(1, client.count)
+	assert.Empty(client.lastCtx.Cookies)
+	_, hasDeadline := client.lastCtx.Context.Deadline()
+	assert.True(hasDeadline)
+
+	assert.Equal(http.StatusOK, w.Result().StatusCode)
+	assert.JSONEq(`{
+		"status": "ok",
+		"checks": {
+			"templates": {"status": "ok"},
+			"static": {"status": "ok"},
+			"sirius": {"status": "ok"}
+		}
+	}`, w.Body.String())
+}
+
+func TestHealthCheckReadyFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockHealthCheckClient{err: errors.New("connection refused")}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/health-check/ready", nil)
+
+	healthCheckReady(client, map[string]*template.Template{}, fstest.MapFS{}, time.Second).ServeHTTP(w, r)
+
+	assert.Equal(http.StatusServiceUnavailable, w.Result().StatusCode)
+	assert.Contains(w.Body.String(), `"status":"fail"`)
+	assert.Contains(w.Body.String(), `"templates":{"status":"fail","error":"no templates loaded"}`)
+	assert.Contains(w.Body.String(), `"sirius":{"status":"fail","error":"connection refused"}`)
+	assert.Contains(w.Body.String(), `"static":{"status":"fail"`)
+}
+
+func TestHealthCheckReadyStaticNotDirectory(t *testing.T) {
+	check := checkStaticDir(fstest.MapFS{"static": &fstest.MapFile{}})
+
+	assert.Equal(t, readinessCheck{Status: "fail", Error: "static is not a directory"}, check)
+}
+
+func TestHealthCheckReadyMissingPage(t *testing.T) {
+	templates := map[string]*template.Template{
+		"users.gotmpl": template.Must(template.New("").Parse(`hello`)),
+	}
+
+	check := checkTemplates(templates)
+	assert.Equal(t, readinessCheck{Status: "fail", Error: "template users.gotmpl does not define page"}, check)
+}
+
+func TestHealthCheckReadySiriusTimeout(t *testing.T) {
+	check := checkSirius(context.Background(), &mockHealthCheckClient{err: context.DeadlineExceeded}, time.Millisecond)
+
+	assert.Equal(t, readinessCheck{Status: "fail", Error: "context deadline exceeded"}, check)
+}
@@ -0,0 +1,281 @@
+package server
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/ministryofjustice/opg-go-common/telemetry"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+const (
+	maxImportUsersFileSize = 1 << 20
+	// maxImportUsersRequestSize allows for the other fields sent with the file
+	maxImportUsersRequestSize = maxImportUsersFileSize + 64<<10
+)
+
+type ImportUsersClient interface {
+	AddUser(ctx sirius.Context, email, firstname, surname, organisation string, roles []string) error
+	Roles(sirius.Context) ([]string, error)
+}
+
+type importUsersVars struct {
+	Path      string
+	XSRFToken string
+	CSV       string
+	Rows      []importUserRow
+	Confirmed bool
+	Errors    sirius.ValidationErrors
+}
+
+func (v importUsersVars) ValidCount() int {
+	count := 0
+	for _, row := range v.Rows {
+		if len(row.Errors) == 0 {
+			count++
+		}
+	}
+
+	return count
+}
+
+type importUserRow struct {
+	Line         int
+	Email        string
+	Firstname    string
+	Surname      string
+	Organisation string
+	Roles        []string
+	Created      bool
+	Errors       sirius.ValidationErrors
+}
+
+func importUsers(client ImportUsersClient, tmpl Template) Handler {
+	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		if !perm.HasPermission("v1-users", http.MethodPost) {
+			return StatusError(http.StatusForbidden)
+		}
+
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			return StatusError(http.StatusMethodNotAllowed)
+		}
+
+		ctx := getContext(r)
+
+		vars := importUsersVars{
+			Path:      r.URL.Path,
+			XSRFToken: ctx.XSRFToken,
+		}
+
+		if r.Method == http.MethodGet {
+			return tmpl.ExecuteTemplate(w, "page", vars)
+		}
+
+		confirm := r.PostFormValue("confirm") != ""
+
+		if confirm {
+			vars.CSV = r.PostFormValue("csv")
+		} else {
+			var maxBytesErr *http.MaxBytesError
+			tooLarge := sirius.ValidationErrors{
+				"file": {"": "The selected file must be smaller than 1MB"},
+			}
+
+			file, _, err := r.FormFile("file")
+			if errors.Is(err, http.ErrMissingFile) {
+				vars.Errors = sirius.ValidationErrors{
+					"file": {"": "Select a CSV file to import"},
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				return tmpl.ExecuteTemplate(w, "page", vars)
+			} else if errors.As(err, &maxBytesErr) {
+				vars.Errors = tooLarge
+				w.WriteHeader(http.StatusBadRequest)
+				return tmpl.ExecuteTemplate(w, "page", vars)
+			} else if err != nil {
+				return StatusError(http.StatusBadRequest)
+			}
+			defer file.Close() //nolint:errcheck // no need to check error when closing file
+
+			data, err := io.ReadAll(io.LimitReader(file, maxImportUsersFileSize+1))
+			if err != nil {
+				return StatusError(http.StatusBadRequest)
+			}
+
+			if len(data) > maxImportUsersFileSize {
+				vars.Errors = tooLarge
+				w.WriteHeader(http.StatusBadRequest)
+				return tmpl.ExecuteTemplate(w, "page", vars)
+			}
+
+			vars.CSV = string(data)
+		}
+
+		roles, err := client.Roles(ctx)
+		if err != nil {
+			return err
+		}
+
+		rows, err := parseImportUsersCSV(vars.CSV, roles)
+		if err != nil {
+			vars.CSV = ""
+			vars.Errors = sirius.ValidationErrors{
+				"file": {"": "The selected file could not be read as CSV: " + err.Error()},
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			return tmpl.ExecuteTemplate(w, "page", vars)
+		}
+
+		if len(rows) == 0 {
+			vars.CSV = ""
+			vars.Errors = sirius.ValidationErrors{
+				"file": {"": "The selected file does not contain any users"},
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			return tmpl.ExecuteTemplate(w, "page", vars)
+		}
+
+		if confirm {
+			for i, row := range rows {
+				if len(row.Errors) > 0 {
+					continue
+				}
+
+				err := client.AddUser(ctx, row.Email, row.Firstname, row.Surname, row.Organisation, row.Roles)
+				recordAudit(r, "add-user", "user:"+row.Email, nil, sirius.AuthUser{
+					Email:        row.Email,
+					Firstname:    row.Firstname,
+					Surname:      row.Surname,
+					Organisation: row.Organisation,
+					Roles:        row.Roles,
+				}, err)
+
+				if verr, ok := err.(sirius.ValidationError); ok {
+					rows[i].Errors = verr.Errors
+				} else if err != nil {
+					// carry on, so that the rows already created are still shown
+					telemetry.LoggerFromContext(r.Context()).Error("could not import user", slog.Any("err", err.Error()), slog.Int("line", row.Line))
+					rows[i].Errors = sirius.ValidationErrors{
+						"#": {"": "The user could not be created, try importing this row again"},
+					}
+				} else {
+					rows[i].Created = true
+				}
+			}
+
+			vars.Confirmed = true
+		}
+
+		vars.Rows = rows
+
+		return tmpl.ExecuteTemplate(w, "page", vars)
+	}
+}
+
+// parseImportUsersCSV reads rows of email, firstname, surname, organisation
+// and roles, where roles are separated by semicolons. A header row starting
+// with "email" is skipped.
+func parseImportUsersCSV(data string, validRoles []string) ([]importUserRow, error) {
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []importUserRow
+	seenEmails := map[string]bool{}
+
+	for i, record := range records {
+		if i == 0 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "email") {
+			continue
+		}
+
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		row := importUserRow{Line: i + 1}
+
+		if len(record) < 4 || len(record) > 5 {
+			row.Errors = sirius.ValidationErrors{
+				"#": {"columns": "Row must have email, first name, last name, organisation and roles columns"},
+			}
+			rows = append(rows, row)
+			continue
+		}
+
+		row.Email = strings.TrimSpace(record[0])
+		row.Firstname = strings.TrimSpace(record[1])
+		row.Surname = strings.TrimSpace(record[2])
+		row.Organisation = strings.TrimSpace(record[3])
+
+		if len(record) == 5 {
+			for _, role := range strings.Split(record[4], ";") {
+				if role = strings.TrimSpace(role); role != "" {
+					row.Roles = append(row.Roles, role)
+				}
+			}
+		}
+
+		row.Errors = validateImportUserRow(row, validRoles, seenEmails)
+		seenEmails[strings.ToLower(row.Email)] = true
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func validateImportUserRow(row importUserRow, validRoles []string, seenEmails map[string]bool) sirius.ValidationErrors {
+	errs := sirius.ValidationErrors{}
+
+	if row.Email == "" {
+		errs["email"] = map[string]string{"isEmpty": "Enter an email address"}
+	} else if !strings.Contains(row.Email, "@") {
+		errs["email"] = map[string]string{"emailAddressInvalidFormat": "Enter a valid email address"}
+	} else if seenEmails[strings.ToLower(row.Email)] {
+		errs["email"] = map[string]string{"duplicate": "Email address appears more than once in the file"}
+	}
+
+	if row.Firstname == "" {
+		errs["firstname"] = map[string]string{"isEmpty": "Enter a first name"}
+	}
+
+	if row.Surname == "" {
+		errs["surname"] = map[string]string{"isEmpty": "Enter a last name"}
+	}
+
+	if row.Organisation != "OPG User" && row.Organisation != "COP User" {
+		errs["organisation"] = map[string]string{"notInArray": "Organisation must be OPG User or COP User"}
+	}
+
+	for _, role := range row.Roles {
+		valid := false
+		for _, validRole := range validRoles {
+			if role == validRole {
+				valid = true
+				break
+			}
+		}
+
+		if !valid {
+			if errs["roles"] == nil {
+				errs["roles"] = map[string]string{}
+			}
+			errs["roles"][role] = "\"" + role + "\" is not a recognised role"
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
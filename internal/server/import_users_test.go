@@ -0,0 +1,330 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockImportUsersClient struct {
+	addUser struct {
+		count      int
+		lastCtx    sirius.Context
+		emails     []string
+		lastRoles  []string
+		errByEmail map[string]error
+	}
+
+	roles struct {
+		count int
+		err   error
+	}
+}
+
+func (m *mockImportUsersClient) AddUser(ctx sirius.Context, email, firstname, surname, organisation string, roles []string) error {
+	m.addUser.count += 1
+	m.addUser.lastCtx = ctx
+	m.addUser.emails = append(m.addUser.emails, email)
+	m.addUser.lastRoles = roles
+
+	return m.addUser.errByEmail[email]
+}
+
+func (m *mockImportUsersClient) Roles(ctx sirius.Context) ([]string, error) {
+	m.roles.count += 1
+
+	return []string{"Finance", "System Admin"}, m.roles.err
+}
+
+func (m *mockImportUsersClient) requiredPermissions() sirius.PermissionSet {
+	return sirius.PermissionSet{"v1-users": sirius.PermissionGroup{Permissions: []string{"post"}}}
+}
+
+func newImportUsersUploadRequest(t *testing.T, data string) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile("file", "users.csv")
+	assert.Nil(t, err)
+	_, _ = part.Write([]byte(data))
+	assert.Nil(t, writer.Close())
+
+	r, _ := http.NewRequest("POST", "/users/import", &body)
+	r.Header.Add("Content-Type", writer.FormDataContentType())
+
+	return r
+}
+
+const importUsersCSV = `email,firstname,surname,organisation,roles
+a@example.com,Anne,Able,OPG User,Finance;System Admin
+b@example.com,Bob,,COP User,Unknown
+`
+
+func TestGetImportUsers(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockImportUsersClient{}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/users/import", nil)
+
+	err := importUsers(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(0, client.roles.count)
+	assert.Equal(1, template.count)
+	assert.Equal(importUsersVars{Path: "/users/import"}, template.lastVars)
+}
+
+func TestImportUsersNoPermission(t *testing.T) {
+	assert := assert.New(t)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/users/import", nil)
+
+	err := importUsers(nil, nil)(sirius.PermissionSet{}, w, r)
+	assert.Equal(StatusError(http.StatusForbidden), err)
+}
+
+func TestPostImportUsersPreview(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockImportUsersClient{}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r := newImportUsersUploadRequest(t, importUsersCSV)
+
+	err := importUsers(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(1, client.roles.count)
+	assert.Equal(0, client.addUser.count)
+
+	assert.Equal(1, template.count)
+	assert.Equal(importUsersVars{
+		Path: "/users/import",
+		CSV:  importUsersCSV,
+		Rows: []importUserRow{
+			{
+				Line:         2,
+				Email:        "a@example.com",
+				Firstname:    "Anne",
+				Surname:      "Able",
+				Organisation: "OPG User",
+				Roles:        []string{"Finance", "System Admin"},
+			},
+			{
+				Line:         3,
+				Email:        "b@example.com",
+				Firstname:    "Bob",
+				Organisation: "COP User",
+				Roles:        []string{"Unknown"},
+				Errors: sirius.ValidationErrors{
+					"surname": {"isEmpty": "Enter a last name"},
+					"roles":   {"Unknown": `"Unknown" is not a recognised role`},
+				},
+			},
+		},
+	}, template.lastVars)
+	assert.Equal(1, template.lastVars.(importUsersVars).ValidCount())
+}
+
+func TestPostImportUsersMissingFile(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockImportUsersClient{}
+	template := &mockTemplate{}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	_ = writer.Close()
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/users/import", &body)
+	r.Header.Add("Content-Type", writer.FormDataContentType())
+
+	err := importUsers(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(http.StatusBadRequest, w.Result().StatusCode)
+	assert.Equal(importUsersVars{
+		Path: "/users/import",
+		Errors: sirius.ValidationErrors{
+			"file": {"": "Select a CSV file to import"},
+		},
+	}, template.lastVars)
+}
+
+func TestPostImportUsersEmptyFile(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockImportUsersClient{}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r := newImportUsersUploadRequest(t, "email,firstname,surname,organisation,roles\n")
+
+	err := importUsers(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(http.StatusBadRequest, w.Result().StatusCode)
+	assert.Equal(sirius.ValidationErrors{
+		"file": {"": "The selected file does not contain any users"},
+	}, template.lastVars.(importUsersVars).Errors)
+}
+
+func TestPostImportUsersConfirm(t *testing.T) {
+	assert := assert.New(t)
+
+	data := importUsersCSV + "c@example.com,Cat,Cole,OPG User,\n"
+
+	client := &mockImportUsersClient{}
+	client.addUser.errByEmail = map[string]error{
+		"c@example.com": sirius.ValidationError{
+			Errors: sirius.ValidationErrors{"email": {"recordExists": "Email is already in use"}},
+		},
+	}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/users/import", strings.NewReader(url.Values{
+		"csv":     {data},
+		"confirm": {"confirm"},
+	}.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := importUsers(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(2, client.addUser.count)
+	assert.Equal(getContext(r), client.addUser.lastCtx)
+	assert.Equal([]string{"a@example.com", "c@example.com"}, client.addUser.emails)
+
+	vars := template.lastVars.(importUsersVars)
+	assert.True(vars.Confirmed)
+	assert.Len(vars.Rows, 3)
+	assert.True(vars.Rows[0].Created)
+	assert.False(vars.Rows[1].Created)
+	assert.False(vars.Rows[2].Created)
+	assert.Equal(sirius.ValidationErrors{"email": {"recordExists": "Email is already in use"}}, vars.Rows[2].Errors)
+}
+
+func TestPostImportUsersConfirmError(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockImportUsersClient{}
+	client.addUser.errByEmail = map[string]error{"a@example.com": errors.New("oops")}
+	template := &mockTemplate{}
+
+	data := importUsersCSV + "c@example.com,Cat,Cole,OPG User,\n"
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/users/import", strings.NewReader(url.Values{
+		"csv":     {data},
+		"confirm": {"confirm"},
+	}.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := importUsers(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal([]string{"a@example.com", "c@example.com"}, client.addUser.emails)
+
+	vars := template.lastVars.(importUsersVars)
+	assert.True(vars.Confirmed)
+	assert.False(vars.Rows[0].Created)
+	assert.Equal(sirius.ValidationErrors{"#": {"": "The user could not be created, try importing this row again"}}, vars.Rows[0].Errors)
+	assert.True(vars.Rows[2].Created)
+}
+
+func TestPostImportUsersTooLarge(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockImportUsersClient{}
+	errorClient := &mockErrorHandlerClient{permissions: client.requiredPermissions()}
+	template := &mockTemplate{}
+
+	handler := limitRequestBody(maxImportUsersRequestSize,
+		errorHandler(errorClient, &mockTemplate{}, "", "http://sirius")(importUsers(client, template)))
+
+	w := httptest.NewRecorder()
+	r := newImportUsersUploadRequest(t, importUsersCSV+strings.Repeat("x", 2<<20))
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(http.StatusBadRequest, w.Result().StatusCode)
+	assert.Equal(0, client.roles.count)
+	assert.Equal(sirius.ValidationErrors{"file": {"": "The selected file must be smaller than 1MB"}}, template.lastVars.(importUsersVars).Errors)
+}
+
+func TestPostImportUsersFileTooLarge(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockImportUsersClient{}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r := newImportUsersUploadRequest(t, importUsersCSV+strings.Repeat("x", maxImportUsersFileSize))
+
+	err := importUsers(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(http.StatusBadRequest, w.Result().StatusCode)
+	assert.Equal(sirius.ValidationErrors{"file": {"": "The selected file must be smaller than 1MB"}}, template.lastVars.(importUsersVars).Errors)
+}
+
+func TestPostImportUsersRolesError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedError := errors.New("oops")
+
+	client := &mockImportUsersClient{}
+	client.roles.err = expectedError
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r := newImportUsersUploadRequest(t, importUsersCSV)
+
+	err := importUsers(client, template)(client.requiredPermissions(), w, r)
+	assert.Equal(expectedError, err)
+	assert.Equal(0, template.count)
+}
+
+func TestParseImportUsersCSV(t *testing.T) {
+	assert := assert.New(t)
+
+	rows, err := parseImportUsersCSV("a@example.com,Anne,Able,OPG User\na@example.com,Anne,Able,OPG User\nnot-an-email,A,B,Other\ntoo,few\n", []string{"Finance"})
+	assert.Nil(err)
+
+	assert.Len(rows, 4)
+	assert.Nil(rows[0].Errors)
+	assert.Equal(sirius.ValidationErrors{
+		"email": {"duplicate": "Email address appears more than once in the file"},
+	}, rows[1].Errors)
+	assert.Equal(sirius.ValidationErrors{
+		"email":        {"emailAddressInvalidFormat": "Enter a valid email address"},
+		"organisation": {"notInArray": "Organisation must be OPG User or COP User"},
+	}, rows[2].Errors)
+	assert.Contains(rows[3].Errors, "#")
+}
+
+func TestImportUsersBadMethod(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockImportUsersClient{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("DELETE", "/users/import", nil)
+
+	err := importUsers(client, nil)(client.requiredPermissions(), w, r)
+	assert.Equal(StatusError(http.StatusMethodNotAllowed), err)
+}
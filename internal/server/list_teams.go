@@ -9,14 +9,22 @@ import (
 
 type ListTeamsClient interface {
 	Teams(sirius.Context) ([]sirius.Team, error)
+	TeamTypes(sirius.Context) ([]sirius.RefDataTeamType, error)
 }
 
 type listTeamsVars struct {
 	Path      string
 	Search    string
+	Service   string
+	Type      string
+	Empty     bool
+	TeamTypes []sirius.RefDataTeamType
 	Teams     []sirius.Team
+	Listing   listing
 }
 
+var listTeamsColumns = []string{"name", "type", "members"}
+
 func listTeams(client ListTeamsClient, tmpl Template) Handler {
 	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
 		if !perm.HasPermission("v1-teams", http.MethodPut) {
@@ -34,6 +42,11 @@ func listTeams(client ListTeamsClient, tmpl Template) Handler {
 			return err
 		}
 
+		teamTypes, err := client.TeamTypes(ctx)
+		if err != nil {
+			return err
+		}
+
 		search := r.FormValue("search")
 		if search != "" {
 			searchLower := strings.ToLower(search)
@@ -48,12 +61,61 @@ func listTeams(client ListTeamsClient, tmpl Template) Handler {
 			teams = matchingTeams
 		}
 
+		service := r.FormValue("service")
+		teamType := r.FormValue("type")
+		empty := r.FormValue("empty") == "1"
+		if teamType != "" {
+			service = "supervision"
+		}
+
+		teams = filterTeams(teams, service, teamType, empty)
+
+		list := getListing(r, listTeamsColumns)
+		sortItems(list, teams, compareTeams)
+		teams = paginate(&list, teams)
+
 		vars := listTeamsVars{
 			Path:      r.URL.Path,
 			Search:    search,
+			Service:   service,
+			Type:      teamType,
+			Empty:     empty,
+			TeamTypes: teamTypes,
 			Teams:     teams,
+			Listing:   list,
 		}
 
 		return tmpl.ExecuteTemplate(w, "page", vars)
 	}
 }
+
+// filterTeams keeps the teams in service ("lpa" or "supervision") with the
+// given supervision team type, leaving out teams with members if only empty
+// ones are wanted. Blank values match any team.
+func filterTeams(teams []sirius.Team, service, teamType string, empty bool) []sirius.Team {
+	var filtered []sirius.Team
+
+	for _, team := range teams {
+		switch {
+		case service == "lpa" && team.Type != "":
+		case service == "supervision" && team.Type == "":
+		case teamType != "" && team.Type != teamType:
+		case empty && len(team.Members) > 0:
+		default:
+			filtered = append(filtered, team)
+		}
+	}
+
+	return filtered
+}
+
+func compareTeams(column string, a, b sirius.Team) int {
+	switch column {
+	case "type":
+		return strings.Compare(strings.ToLower(a.TypeLabel), strings.ToLower(b.TypeLabel))
+	case "members":
+		return len(a.Members) - len(b.Members)
+	default:
+		return strings.Compare(strings.ToLower(a.DisplayName), strings.ToLower(b.DisplayName))
+	}
+}
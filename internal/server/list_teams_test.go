@@ -2,8 +2,10 @@ package server
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
@@ -15,6 +17,10 @@ type mockListTeamsClient struct {
 	lastCtx sirius.Context
 	err     error
 	data    []sirius.Team
+
+	teamTypesCount int
+	teamTypesData  []sirius.RefDataTeamType
+	teamTypesErr   error
 }
 
 func (m *mockListTeamsClient) Teams(ctx sirius.Context) ([]sirius.Team, error) {
@@ -24,6 +30,13 @@ func (m *mockListTeamsClient) Teams(ctx sirius.Context) ([]sirius.Team, error) {
 	return m.data, m.err
 }
 
+func (m *mockListTeamsClient) TeamTypes(ctx sirius.Context) ([]sirius.RefDataTeamType, error) {
+	m.teamTypesCount += 1
+	m.lastCtx = ctx
+
+	return m.teamTypesData, m.teamTypesErr
+}
+
 func (m *mockListTeamsClient) requiredPermissions() sirius.PermissionSet {
 	return sirius.PermissionSet{"v1-teams": sirius.PermissionGroup{Permissions: []string{"put"}}}
 }
@@ -61,6 +74,13 @@ func TestListTeams(t *testing.T) {
 	assert.Equal(listTeamsVars{
 		Path:  "/path",
 		Teams: data,
+		Listing: listing{
+			query:      url.Values{},
+			Sort:       "name",
+			Page:       1,
+			PageSize:   25,
+			TotalItems: 1,
+		},
 	}, template.lastVars)
 }
 
@@ -121,6 +141,13 @@ func TestListTeamsSearch(t *testing.T) {
 				Type:        "Top Notch",
 			},
 		},
+		Listing: listing{
+			query:      url.Values{"search": {"milo"}},
+			Sort:       "name",
+			Page:       1,
+			PageSize:   25,
+			TotalItems: 1,
+		},
 	}, template.lastVars)
 }
 
@@ -150,3 +177,104 @@ func TestPostListTeams(t *testing.T) {
 	err := listTeams(nil, nil)(client.requiredPermissions(), w, r)
 	assert.Equal(StatusError(http.StatusMethodNotAllowed), err)
 }
+
+func TestListTeamsSortedAndPaginated(t *testing.T) {
+	assert := assert.New(t)
+
+	var data []sirius.Team
+	for i := 1; i <= 30; i++ {
+		data = append(data, sirius.Team{
+			ID:          i,
+			DisplayName: fmt.Sprintf("Team %02d", i),
+			Members:     make([]sirius.TeamMember, i%4),
+		})
+	}
+
+	client := &mockListTeamsClient{data: data}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/path?sort=members&order=desc&page=2&size=25", nil)
+
+	err := listTeams(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	vars := template.lastVars.(listTeamsVars)
+	assert.Equal(2, vars.Listing.Page)
+	assert.Equal(30, vars.Listing.TotalItems)
+	assert.Len(vars.Teams, 5)
+
+	for _, team := range vars.Teams {
+		assert.Equal(0, len(team.Members))
+	}
+	assert.Equal("Team 12", vars.Teams[0].DisplayName)
+}
+
+func TestListTeamsFiltered(t *testing.T) {
+	data := []sirius.Team{
+		{ID: 1, DisplayName: "LPA Team", TypeLabel: "LPA", Members: make([]sirius.TeamMember, 2)},
+		{ID: 2, DisplayName: "Empty LPA Team", TypeLabel: "LPA"},
+		{ID: 3, DisplayName: "Finance Team", Type: "FINANCE", TypeLabel: "Supervision — Finance", Members: make([]sirius.TeamMember, 1)},
+		{ID: 4, DisplayName: "Allocations Team", Type: "ALLOCATIONS", TypeLabel: "Supervision — Allocations"},
+	}
+	teamTypes := []sirius.RefDataTeamType{
+		{Handle: "ALLOCATIONS", Label: "Allocations"},
+		{Handle: "FINANCE", Label: "Finance"},
+	}
+
+	testCases := map[string]struct {
+		query   string
+		service string
+		ids     []int
+	}{
+		"lpa":                {query: "service=lpa", service: "lpa", ids: []int{2, 1}},
+		"supervision":        {query: "service=supervision", service: "supervision", ids: []int{4, 3}},
+		"type":               {query: "type=FINANCE", service: "supervision", ids: []int{3}},
+		"type overrides lpa": {query: "service=lpa&type=FINANCE", service: "supervision", ids: []int{3}},
+		"empty":              {query: "empty=1", ids: []int{4, 2}},
+		"empty lpa":          {query: "service=lpa&empty=1", service: "lpa", ids: []int{2}},
+		"no match":           {query: "type=FINANCE&empty=1", service: "supervision"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			client := &mockListTeamsClient{data: data, teamTypesData: teamTypes}
+			template := &mockTemplate{}
+
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest("GET", "/path?"+tc.query, nil)
+
+			err := listTeams(client, template)(client.requiredPermissions(), w, r)
+			assert.Nil(err)
+			assert.Equal(1, client.teamTypesCount)
+
+			vars := template.lastVars.(listTeamsVars)
+			assert.Equal(tc.service, vars.Service)
+			assert.Equal(teamTypes, vars.TeamTypes)
+
+			var ids []int
+			for _, team := range vars.Teams {
+				ids = append(ids, team.ID)
+			}
+			assert.Equal(tc.ids, ids)
+		})
+	}
+}
+
+func TestListTeamsTeamTypesError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedErr := errors.New("err")
+	client := &mockListTeamsClient{teamTypesErr: expectedErr}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/path", nil)
+
+	err := listTeams(client, template)(client.requiredPermissions(), w, r)
+
+	assert.Equal(expectedErr, err)
+	assert.Equal(0, template.count)
+}
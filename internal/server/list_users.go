@@ -2,6 +2,9 @@ package server
 
 import (
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
 )
@@ -11,12 +14,18 @@ type ListUsersClient interface {
 }
 
 type listUsersVars struct {
-	Path   string
-	Users  []sirius.User
-	Search string
-	Errors sirius.ValidationErrors
+	Path      string
+	XSRFToken string
+	Users     []sirius.User
+	Search    string
+	Teams     []sirius.UserTeam
+	Team      int
+	Listing   listing
+	Errors    sirius.ValidationErrors
 }
 
+var listUsersColumns = []string{"name", "email", "status", "team"}
+
 func listUsers(client ListUsersClient, tmpl Template) Handler {
 	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
 		if !perm.HasPermission("v1-users", http.MethodPut) {
@@ -27,15 +36,20 @@ func listUsers(client ListUsersClient, tmpl Template) Handler {
 			return StatusError(http.StatusMethodNotAllowed)
 		}
 
+		ctx := getContext(r)
 		search := r.FormValue("search")
+		team, _ := strconv.Atoi(r.FormValue("team"))
 
 		vars := listUsersVars{
-			Path:   r.URL.Path,
-			Search: search,
+			Path:      r.URL.Path,
+			XSRFToken: ctx.XSRFToken,
+			Search:    search,
+			Team:      team,
+			Listing:   getListing(r, listUsersColumns),
 		}
 
 		if search != "" {
-			users, err := client.SearchUsers(getContext(r), search)
+			users, err := client.SearchUsers(ctx, search)
 
 			if _, ok := err.(sirius.ClientError); ok {
 				vars.Errors = sirius.ValidationErrors{
@@ -46,10 +60,62 @@ func listUsers(client ListUsersClient, tmpl Template) Handler {
 			} else if err != nil {
 				return err
 			} else {
-				vars.Users = users
+				vars.Teams = getUsersTeams(users)
+				users = filterUsersByTeam(users, team)
+				sortItems(vars.Listing, users, compareUsers)
+				vars.Users = paginate(&vars.Listing, users)
 			}
 		}
 
 		return tmpl.ExecuteTemplate(w, "page", vars)
 	}
 }
+
+// getUsersTeams lists each team that any of the users are in, ordered by name.
+func getUsersTeams(users []sirius.User) []sirius.UserTeam {
+	var teams []sirius.UserTeam
+	seen := map[int]bool{}
+
+	for _, user := range users {
+		for _, team := range user.Teams {
+			if !seen[team.ID] {
+				teams = append(teams, team)
+				seen[team.ID] = true
+			}
+		}
+	}
+
+	sort.SliceStable(teams, func(i, j int) bool {
+		return strings.ToLower(teams[i].DisplayName) < strings.ToLower(teams[j].DisplayName)
+	})
+
+	return teams
+}
+
+func filterUsersByTeam(users []sirius.User, team int) []sirius.User {
+	if team == 0 {
+		return users
+	}
+
+	var filtered []sirius.User
+	for _, user := range users {
+		if user.InTeam(team) {
+			filtered = append(filtered, user)
+		}
+	}
+
+	return filtered
+}
+
+func compareUsers(column string, a, b sirius.User) int {
+	switch column {
+	case "email":
+		return strings.Compare(strings.ToLower(a.Email), strings.ToLower(b.Email))
+	case "status":
+		return strings.Compare(a.Status.String(), b.Status.String())
+	case "team":
+		return strings.Compare(strings.ToLower(strings.Join(a.TeamNames(), ", ")), strings.ToLower(strings.Join(b.TeamNames(), ", ")))
+	default:
+		return strings.Compare(strings.ToLower(a.DisplayName), strings.ToLower(b.DisplayName))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
@@ -74,6 +75,13 @@ func TestListUsers(t *testing.T) {
 				Status:      "Active",
 			},
 		},
+		Listing: listing{
+			query:      url.Values{"search": {"milo"}},
+			Sort:       "name",
+			Page:       1,
+			PageSize:   25,
+			TotalItems: 1,
+		},
 	}, template.lastVars)
 }
 
@@ -119,9 +127,10 @@ func TestListUsersRequiresSearch(t *testing.T) {
 	assert.Equal(1, template.count)
 	assert.Equal("page", template.lastName)
 	assert.Equal(listUsersVars{
-		Path:   "/path",
-		Search: "",
-		Users:  nil,
+		Path:    "/path",
+		Search:  "",
+		Users:   nil,
+		Listing: listing{query: url.Values{}, Sort: "name", Page: 1, PageSize: 25},
 	}, template.lastVars)
 }
 
@@ -149,9 +158,10 @@ func TestListUsersClientError(t *testing.T) {
 	assert.Equal(1, template.count)
 	assert.Equal("page", template.lastName)
 	assert.Equal(listUsersVars{
-		Path:   "/path",
-		Search: "m",
-		Users:  nil,
+		Path:    "/path",
+		Search:  "m",
+		Users:   nil,
+		Listing: listing{query: url.Values{"search": {"m"}}, Sort: "name", Page: 1, PageSize: 25},
 		Errors: sirius.ValidationErrors{
 			"search": {
 				"": "problem",
@@ -192,3 +202,71 @@ func TestPostListUsers(t *testing.T) {
 
 	assert.Equal(0, template.count)
 }
+
+func TestListUsersFilteredByTeam(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockListUsersClient{
+		data: []sirius.User{
+			{ID: 29, DisplayName: "Milo Nihei", Teams: []sirius.UserTeam{{ID: 2, DisplayName: "Visits"}, {ID: 1, DisplayName: "allocations"}}},
+			{ID: 30, DisplayName: "Anton Mccoy", Teams: []sirius.UserTeam{{ID: 2, DisplayName: "Visits"}}},
+			{ID: 31, DisplayName: "Darian Kramer"},
+		},
+	}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/path?search=milo&team=1", nil)
+
+	err := listUsers(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(listUsersVars{
+		Path:   "/path",
+		Search: "milo",
+		Team:   1,
+		Teams: []sirius.UserTeam{
+			{ID: 1, DisplayName: "allocations"},
+			{ID: 2, DisplayName: "Visits"},
+		},
+		Users: []sirius.User{client.data[0]},
+		Listing: listing{
+			query:      url.Values{"search": {"milo"}, "team": {"1"}},
+			Sort:       "name",
+			Page:       1,
+			PageSize:   25,
+			TotalItems: 1,
+		},
+	}, template.lastVars)
+}
+
+func TestListUsersSorted(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockListUsersClient{
+		data: []sirius.User{
+			{ID: 1, DisplayName: "Anton Mccoy", Email: "c@example.com", Status: "Active"},
+			{ID: 2, DisplayName: "Milo Nihei", Email: "a@example.com", Status: "Suspended"},
+			{ID: 3, DisplayName: "Darian Kramer", Email: "b@example.com", Status: "Active"},
+		},
+	}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/path?search=a&sort=email&order=desc&page=4", nil)
+
+	err := listUsers(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	vars := template.lastVars.(listUsersVars)
+	assert.Equal("email", vars.Listing.Sort)
+	assert.True(vars.Listing.Descending)
+	assert.Equal(1, vars.Listing.Page)
+	assert.Equal(3, vars.Listing.TotalItems)
+
+	var ids []int
+	for _, user := range vars.Users {
+		ids = append(ids, user.ID)
+	}
+	assert.Equal([]int{1, 3, 2}, ids)
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+)
+
+const defaultPageSize = 25
+
+var pageSizes = []int{25, 50, 100}
+
+// listing holds the paging and sort state of a table, read from and written
+// back to the query string so that links keep the current view.
+type listing struct {
+	query url.Values
+
+	Sort       string
+	Descending bool
+	Page       int
+	PageSize   int
+	TotalItems int
+}
+
+// getListing reads the state of a table from the request. Any sort that is
+// not in columns is replaced by the first of them.
+func getListing(r *http.Request, columns []string) listing {
+	l := listing{
+		query:      url.Values{},
+		Sort:       r.FormValue("sort"),
+		Descending: r.FormValue("order") == "desc",
+		PageSize:   defaultPageSize,
+		Page:       1,
+	}
+
+	for key, values := range r.URL.Query() {
+		l.query[key] = values
+	}
+
+	if !slices.Contains(columns, l.Sort) {
+		l.Sort = columns[0]
+		l.Descending = false
+	}
+
+	if size, err := strconv.Atoi(r.FormValue("size")); err == nil && slices.Contains(pageSizes, size) {
+		l.PageSize = size
+	}
+
+	if page, err := strconv.Atoi(r.FormValue("page")); err == nil && page > 1 {
+		l.Page = page
+	}
+
+	return l
+}
+
+// paginate returns the items on the current page, moving to the last page if
+// the requested one is beyond it.
+func paginate[T any](l *listing, items []T) []T {
+	l.TotalItems = len(items)
+
+	if l.Page > l.TotalPages() {
+		l.Page = max(l.TotalPages(), 1)
+	}
+
+	start := min((l.Page-1)*l.PageSize, len(items))
+	end := min(start+l.PageSize, len(items))
+
+	return items[start:end]
+}
+
+// sortItems orders items by the sort column using less, which should compare
+// items in ascending order for the given column.
+func sortItems[T any](l listing, items []T, less func(column string, a, b T) int) {
+	slices.SortStableFunc(items, func(a, b T) int {
+		if l.Descending {
+			return less(l.Sort, b, a)
+		}
+		return less(l.Sort, a, b)
+	})
+}
+
+func (l listing) TotalPages() int {
+	return (l.TotalItems + l.PageSize - 1) / l.PageSize
+}
+
+func (l listing) From() int {
+	if l.TotalItems == 0 {
+		return 0
+	}
+	return (l.Page-1)*l.PageSize + 1
+}
+
+func (l listing) To() int {
+	return min(l.Page*l.PageSize, l.TotalItems)
+}
+
+func (l listing) Pages() []int {
+	pages := make([]int, l.TotalPages())
+	for i := range pages {
+		pages[i] = i + 1
+	}
+	return pages
+}
+
+func (l listing) PreviousPage() int {
+	return l.Page - 1
+}
+
+func (l listing) NextPage() int {
+	return l.Page + 1
+}
+
+func (l listing) PageSizes() []int {
+	return pageSizes
+}
+
+func (l listing) link(set map[string]string) string {
+	query := url.Values{}
+	for key, values := range l.query {
+		query[key] = values
+	}
+
+	for key, value := range set {
+		if value == "" {
+			query.Del(key)
+		} else {
+			query.Set(key, value)
+		}
+	}
+
+	return "?" + query.Encode()
+}
+
+// PageLink is the query string for another page of the table.
+func (l listing) PageLink(page int) string {
+	return l.link(map[string]string{"page": strconv.Itoa(page)})
+}
+
+// PageSizeLink is the query string to show size rows per page, starting
+// again from the first page.
+func (l listing) PageSizeLink(size int) string {
+	return l.link(map[string]string{"size": strconv.Itoa(size), "page": ""})
+}
+
+// SortLink is the query string to sort by column, reversing the order if the
+// table is already sorted by it.
+func (l listing) SortLink(column string) string {
+	order := ""
+	if column == l.Sort && !l.Descending {
+		order = "desc"
+	}
+
+	return l.link(map[string]string{"sort": column, "order": order, "page": ""})
+}
+
+// AriaSort is the value of the aria-sort attribute for a column header.
+func (l listing) AriaSort(column string) string {
+	switch {
+	case column != l.Sort:
+		return "none"
+	case l.Descending:
+		return "descending"
+	default:
+		return "ascending"
+	}
+}
+
+type sortHeader struct {
+	Listing listing
+	Column  string
+	Label   string
+}
+
+// Header provides the values needed by the sortable-header template.
+func (l listing) Header(column, label string) sortHeader {
+	return sortHeader{Listing: l, Column: column, Label: label}
+}
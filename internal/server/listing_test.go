@@ -0,0 +1,96 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetListing(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/path?search=a&sort=email&order=desc&page=3&size=50", nil)
+
+	assert.Equal(t, listing{
+		query:      url.Values{"search": {"a"}, "sort": {"email"}, "order": {"desc"}, "page": {"3"}, "size": {"50"}},
+		Sort:       "email",
+		Descending: true,
+		Page:       3,
+		PageSize:   50,
+	}, getListing(r, []string{"name", "email"}))
+}
+
+func TestGetListingDefaults(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/path?sort=password&order=desc&page=-1&size=1000", nil)
+
+	l := getListing(r, []string{"name", "email"})
+	assert.Equal(t, "name", l.Sort)
+	assert.False(t, l.Descending)
+	assert.Equal(t, 1, l.Page)
+	assert.Equal(t, defaultPageSize, l.PageSize)
+}
+
+func TestPaginate(t *testing.T) {
+	items := make([]int, 60)
+	for i := range items {
+		items[i] = i
+	}
+
+	l := listing{Page: 2, PageSize: 25}
+	page := paginate(&l, items)
+
+	assert.Equal(t, items[25:50], page)
+	assert.Equal(t, 60, l.TotalItems)
+	assert.Equal(t, 3, l.TotalPages())
+	assert.Equal(t, 26, l.From())
+	assert.Equal(t, 50, l.To())
+	assert.Equal(t, []int{1, 2, 3}, l.Pages())
+
+	l = listing{Page: 9, PageSize: 25}
+	page = paginate(&l, items)
+
+	assert.Equal(t, 3, l.Page)
+	assert.Equal(t, items[50:], page)
+	assert.Equal(t, 60, l.To())
+}
+
+func TestPaginateEmpty(t *testing.T) {
+	l := listing{Page: 2, PageSize: 25}
+	page := paginate(&l, []int{})
+
+	assert.Empty(t, page)
+	assert.Equal(t, 1, l.Page)
+	assert.Equal(t, 0, l.From())
+	assert.Equal(t, 0, l.To())
+}
+
+func TestSortItems(t *testing.T) {
+	items := []string{"b", "C", "a"}
+	less := func(column string, a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	}
+
+	sortItems(listing{Sort: "name"}, items, less)
+	assert.Equal(t, []string{"a", "b", "C"}, items)
+
+	sortItems(listing{Sort: "name", Descending: true}, items, less)
+	assert.Equal(t, []string{"C", "b", "a"}, items)
+}
+
+func TestListingLinks(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/path?search=a&sort=name&page=2", nil)
+	l := getListing(r, []string{"name", "email"})
+
+	assert.Equal(t, "?page=3&search=a&sort=name", l.PageLink(3))
+	assert.Equal(t, "?search=a&size=50&sort=name", l.PageSizeLink(50))
+	assert.Equal(t, "?order=desc&search=a&sort=name", l.SortLink("name"))
+	assert.Equal(t, "?search=a&sort=email", l.SortLink("email"))
+
+	assert.Equal(t, "ascending", l.AriaSort("name"))
+	assert.Equal(t, "none", l.AriaSort("email"))
+
+	l.Descending = true
+	assert.Equal(t, "?search=a&sort=name", l.SortLink("name"))
+	assert.Equal(t, "descending", l.AriaSort("name"))
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/metrics"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+type metricsKey struct{}
+
+type requestMetrics struct {
+	metrics *metrics.Metrics
+	status  int
+}
+
+type metricsResponseWriter struct {
+	http.ResponseWriter
+	rm *requestMetrics
+}
+
+func (w *metricsResponseWriter) WriteHeader(code int) {
+	if w.rm.status == 0 {
+		w.rm.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *metricsResponseWriter) Write(b []byte) (int, error) {
+	if w.rm.status == 0 {
+		w.rm.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *metricsResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// withMetrics records the count and duration of requests by the route they
+// matched, and makes the collectors available to errorHandler. It must wrap
+// the mux directly so that the matched pattern can be read.
+func withMetrics(m *metrics.Metrics, now func() time.Time) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if m == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := now()
+			rm := &requestMetrics{metrics: m}
+
+			r = r.WithContext(context.WithValue(r.Context(), metricsKey{}, rm))
+			next.ServeHTTP(&metricsResponseWriter{ResponseWriter: w, rm: rm}, r)
+
+			if rm.status == 0 {
+				rm.status = http.StatusOK
+			}
+
+			m.ObserveRequest(routeLabel(r), rm.status, now().Sub(start))
+		})
+	}
+}
+
+func routeLabel(r *http.Request) string {
+	if r.Pattern == "" {
+		return "unmatched"
+	}
+
+	return r.Pattern
+}
+
+// recordHandlerMetrics counts the outcomes of a handler that are worth
+// alerting on: requests refused for lack of permission, and submissions that
+// failed validation, whether returned as an error or rendered with a 400.
+func recordHandlerMetrics(r *http.Request, err error) {
+	rm, _ := r.Context().Value(metricsKey{}).(*requestMetrics)
+	if rm == nil {
+		return
+	}
+
+	var (
+		status StatusError
+		verr   sirius.ValidationError
+	)
+
+	switch {
+	case errors.As(err, &status) && status.Code() == http.StatusForbidden:
+		rm.metrics.PermissionDenied(routeLabel(r))
+	case errors.As(err, &verr), err == nil && rm.status == http.StatusBadRequest:
+		rm.metrics.ValidationFailed(routeLabel(r))
+	}
+}
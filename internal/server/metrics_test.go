@@ -0,0 +1,86 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/metrics"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+func scrapeMetrics(m *metrics.Metrics) string {
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/metrics", nil)
+	m.Handler().ServeHTTP(w, r)
+
+	return w.Body.String()
+}
+
+func TestWithMetrics(t *testing.T) {
+	assert := assert.New(t)
+
+	m := metrics.New()
+	client := &mockErrorHandlerClient{}
+	wrap := errorHandler(client, &mockTemplate{}, "", "http://sirius")
+
+	mux := http.NewServeMux()
+	mux.Handle("/teams/", wrap(func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}))
+	mux.Handle("/teams/add", wrap(func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusBadRequest)
+		return nil
+	}))
+	mux.Handle("/users", wrap(func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		return StatusError(http.StatusForbidden)
+	}))
+
+	now := time.Now()
+	handler := withMetrics(m, func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	})(mux)
+
+	for _, path := range []string{"/teams/1", "/teams/2", "/teams/add", "/users", "/nothing"} {
+		r, _ := http.NewRequest("GET", path, nil)
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	body := scrapeMetrics(m)
+	assert.Contains(body, `user_management_http_requests_total{code="200",route="/teams/"} 2`)
+	assert.Contains(body, `user_management_http_requests_total{code="400",route="/teams/add"} 1`)
+	assert.Contains(body, `user_management_http_requests_total{code="403",route="/users"} 1`)
+	assert.Contains(body, `user_management_http_requests_total{code="404",route="unmatched"} 1`)
+	assert.Contains(body, `user_management_http_request_duration_seconds_sum{code="200",route="/teams/"} 2`)
+	assert.Contains(body, `user_management_validation_failures_total{route="/teams/add"} 1`)
+	assert.Contains(body, `user_management_permission_denials_total{route="/users"} 1`)
+}
+
+func TestWithMetricsValidationError(t *testing.T) {
+	assert := assert.New(t)
+
+	m := metrics.New()
+	client := &mockErrorHandlerClient{}
+	wrap := apiErrorHandler(client)
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/users", wrap(func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		return sirius.ValidationError{Message: "Invalid"}
+	}))
+
+	r, _ := http.NewRequest("GET", "/api/users", nil)
+	withMetrics(m, time.Now)(mux).ServeHTTP(httptest.NewRecorder(), r)
+
+	body := scrapeMetrics(m)
+	assert.Contains(body, `user_management_http_requests_total{code="400",route="/api/users"} 1`)
+	assert.Contains(body, `user_management_validation_failures_total{route="/api/users"} 1`)
+}
+
+func TestWithMetricsNil(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	assert.NotNil(t, withMetrics(nil, time.Now)(handler))
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+type MoveTeamMembersClient interface {
+	Team(sirius.Context, int) (sirius.Team, error)
+	Teams(sirius.Context) ([]sirius.Team, error)
+	EditTeam(sirius.Context, sirius.Team) error
+}
+
+type moveTeamMembersVars struct {
+	Path        string
+	XSRFToken   string
+	Team        sirius.Team
+	Teams       []sirius.Team
+	Selected    map[int]string
+	Destination int
+	Errors      sirius.ValidationErrors
+}
+
+func moveTeamMembers(client MoveTeamMembersClient, tmpl Template) Handler {
+	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		if !perm.HasPermission("v1-teams", http.MethodPut) {
+			return StatusError(http.StatusForbidden)
+		}
+
+		if r.Method != http.MethodPost {
+			return StatusError(http.StatusMethodNotAllowed)
+		}
+
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/teams/move-members/"))
+		if err != nil {
+			return StatusError(http.StatusNotFound)
+		}
+
+		if err := r.ParseForm(); err != nil {
+			return StatusError(http.StatusBadRequest)
+		}
+
+		ctx := getContext(r)
+
+		team, err := client.Team(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		teams, err := client.Teams(ctx)
+		if err != nil {
+			return err
+		}
+
+		vars := moveTeamMembersVars{
+			Path:      r.URL.Path,
+			XSRFToken: ctx.XSRFToken,
+			Team:      team,
+			Selected:  make(map[int]string),
+		}
+
+		for _, t := range teams {
+			if t.ID != team.ID {
+				vars.Teams = append(vars.Teams, t)
+			}
+		}
+
+		for _, id := range r.PostForm["selected[]"] {
+			userID, err := strconv.Atoi(id)
+			if err != nil {
+				return StatusError(http.StatusBadRequest)
+			}
+
+			for _, user := range team.Members {
+				if userID == user.ID {
+					vars.Selected[userID] = user.DisplayName
+				}
+			}
+		}
+
+		if r.PostFormValue("confirm") == "" {
+			return tmpl.ExecuteTemplate(w, "page", vars)
+		}
+
+		vars.Destination, _ = strconv.Atoi(r.PostFormValue("destination"))
+
+		if len(vars.Selected) == 0 {
+			vars.Errors = sirius.ValidationErrors{
+				"#": {"": "Select at least one member to move"},
+			}
+		} else if vars.Destination == 0 || vars.Destination == team.ID {
+			vars.Errors = sirius.ValidationErrors{
+				"destination": {"": "Select a team to move members to"},
+			}
+		}
+
+		if vars.Errors != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return tmpl.ExecuteTemplate(w, "page", vars)
+		}
+
+		destination, err := client.Team(ctx, vars.Destination)
+		if err != nil {
+			return err
+		}
+
+		before := []sirius.Team{team, destination}
+
+		source := team
+		source.Members = nil
+		for _, member := range team.Members {
+			if _, ok := vars.Selected[member.ID]; !ok {
+				source.Members = append(source.Members, member)
+			}
+		}
+
+		existing := map[int]bool{}
+		for _, member := range destination.Members {
+			existing[member.ID] = true
+		}
+
+		for _, member := range team.Members {
+			if _, ok := vars.Selected[member.ID]; ok && !existing[member.ID] {
+				destination.Members = append(destination.Members, member)
+			}
+		}
+
+		err = client.EditTeam(ctx, source)
+
+		if err == nil {
+			err = client.EditTeam(ctx, destination)
+
+			if err != nil {
+				// Put the members back so that nobody is left without a team
+				rerr := client.EditTeam(ctx, team)
+				recordAudit(r, "edit-team", fmt.Sprintf("team:%d", team.ID), source, team, rerr)
+				if rerr != nil {
+					return fmt.Errorf("could not restore members of team %d after failed move: %w", team.ID, rerr)
+				}
+			}
+		}
+
+		recordAudit(r, "move-team-members", fmt.Sprintf("team:%d", team.ID), before, []sirius.Team{source, destination}, err)
+
+		if verr, ok := err.(sirius.ValidationError); ok {
+			vars.Errors = verr.Errors
+			w.WriteHeader(http.StatusBadRequest)
+			return tmpl.ExecuteTemplate(w, "page", vars)
+		} else if err != nil {
+			return err
+		}
+
+		return RedirectError(fmt.Sprintf("/teams/%d", destination.ID))
+	}
+}
@@ -0,0 +1,254 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockMoveTeamMembersClient struct {
+	team struct {
+		count   int
+		lastIDs []int
+		data    map[int]sirius.Team
+		err     error
+	}
+	teams struct {
+		count int
+		data  []sirius.Team
+		err   error
+	}
+	editTeam struct {
+		count   int
+		lastCtx sirius.Context
+		teams   []sirius.Team
+		errs    []error
+	}
+}
+
+func (m *mockMoveTeamMembersClient) Team(ctx sirius.Context, id int) (sirius.Team, error) {
+	m.team.count += 1
+	m.team.lastIDs = append(m.team.lastIDs, id)
+
+	return m.team.data[id], m.team.err
+}
+
+func (m *mockMoveTeamMembersClient) Teams(ctx sirius.Context) ([]sirius.Team, error) {
+	m.teams.count += 1
+
+	return m.teams.data, m.teams.err
+}
+
+func (m *mockMoveTeamMembersClient) EditTeam(ctx sirius.Context, team sirius.Team) error {
+	m.editTeam.count += 1
+	m.editTeam.lastCtx = ctx
+	m.editTeam.teams = append(m.editTeam.teams, team)
+
+	if len(m.editTeam.errs) >= m.editTeam.count {
+		return m.editTeam.errs[m.editTeam.count-1]
+	}
+
+	return nil
+}
+
+func (m *mockMoveTeamMembersClient) requiredPermissions() sirius.PermissionSet {
+	return sirius.PermissionSet{"v1-teams": sirius.PermissionGroup{Permissions: []string{"put"}}}
+}
+
+func newMockMoveTeamMembersClient() *mockMoveTeamMembersClient {
+	source := generateTeamWithIds(12, 16, 45)
+	destination := sirius.Team{
+		ID:          200,
+		DisplayName: "Destination",
+		Members:     []sirius.TeamMember{{ID: 45, DisplayName: "User 45"}, {ID: 99, DisplayName: "User 99"}},
+	}
+
+	client := &mockMoveTeamMembersClient{}
+	client.team.data = map[int]sirius.Team{123: source, 200: destination}
+	client.teams.data = []sirius.Team{{ID: 123, DisplayName: "Source"}, {ID: 200, DisplayName: "Destination"}}
+
+	return client
+}
+
+func TestPostMoveTeamMembers(t *testing.T) {
+	assert := assert.New(t)
+
+	client := newMockMoveTeamMembersClient()
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/teams/move-members/123", strings.NewReader("selected[]=12&selected[]=45"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := moveTeamMembers(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal([]int{123}, client.team.lastIDs)
+	assert.Equal(0, client.editTeam.count)
+
+	assert.Equal(1, template.count)
+	assert.Equal("page", template.lastName)
+	assert.Equal(moveTeamMembersVars{
+		Path:  "/teams/move-members/123",
+		Team:  client.team.data[123],
+		Teams: []sirius.Team{{ID: 200, DisplayName: "Destination"}},
+		Selected: map[int]string{
+			12: "User 12",
+			45: "User 45",
+		},
+	}, template.lastVars)
+}
+
+func TestPostMoveTeamMembersConfirm(t *testing.T) {
+	assert := assert.New(t)
+
+	client := newMockMoveTeamMembersClient()
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/teams/move-members/123", strings.NewReader("selected[]=12&selected[]=45&destination=200&confirm=confirm"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := moveTeamMembers(client, template)(client.requiredPermissions(), w, r)
+	assert.Equal(RedirectError("/teams/200"), err)
+
+	assert.Equal([]int{123, 200}, client.team.lastIDs)
+	assert.Equal(2, client.editTeam.count)
+	assert.Equal(getContext(r), client.editTeam.lastCtx)
+
+	assert.Equal([]sirius.TeamMember{{ID: 16, DisplayName: "User 16"}}, client.editTeam.teams[0].Members)
+	assert.Equal(123, client.editTeam.teams[0].ID)
+
+	assert.Equal([]sirius.TeamMember{
+		{ID: 45, DisplayName: "User 45"},
+		{ID: 99, DisplayName: "User 99"},
+		{ID: 12, DisplayName: "User 12"},
+	}, client.editTeam.teams[1].Members)
+	assert.Equal(200, client.editTeam.teams[1].ID)
+
+	assert.Equal(0, template.count)
+}
+
+func TestPostMoveTeamMembersRevertsWhenAddFails(t *testing.T) {
+	assert := assert.New(t)
+
+	client := newMockMoveTeamMembersClient()
+	client.editTeam.errs = []error{nil, sirius.ValidationError{
+		Errors: sirius.ValidationErrors{"memberIds": {"invalid": "Members are invalid"}},
+	}}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/teams/move-members/123", strings.NewReader("selected[]=12&destination=200&confirm=confirm"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := moveTeamMembers(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(3, client.editTeam.count)
+	assert.Equal(client.team.data[123], client.editTeam.teams[2])
+
+	assert.Equal(http.StatusBadRequest, w.Result().StatusCode)
+	assert.Equal(sirius.ValidationErrors{"memberIds": {"invalid": "Members are invalid"}}, template.lastVars.(moveTeamMembersVars).Errors)
+}
+
+func TestPostMoveTeamMembersRevertFails(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedError := errors.New("oops")
+
+	client := newMockMoveTeamMembersClient()
+	client.editTeam.errs = []error{nil, errors.New("add failed"), expectedError}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/teams/move-members/123", strings.NewReader("selected[]=12&destination=200&confirm=confirm"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := moveTeamMembers(client, template)(client.requiredPermissions(), w, r)
+	assert.ErrorIs(err, expectedError)
+	assert.Equal(3, client.editTeam.count)
+}
+
+func TestPostMoveTeamMembersRemoveFails(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedError := errors.New("oops")
+
+	client := newMockMoveTeamMembersClient()
+	client.editTeam.errs = []error{expectedError}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/teams/move-members/123", strings.NewReader("selected[]=12&destination=200&confirm=confirm"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := moveTeamMembers(client, template)(client.requiredPermissions(), w, r)
+	assert.Equal(expectedError, err)
+	assert.Equal(1, client.editTeam.count)
+}
+
+func TestPostMoveTeamMembersNoDestination(t *testing.T) {
+	assert := assert.New(t)
+
+	client := newMockMoveTeamMembersClient()
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/teams/move-members/123", strings.NewReader("selected[]=12&confirm=confirm"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := moveTeamMembers(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(http.StatusBadRequest, w.Result().StatusCode)
+	assert.Equal(0, client.editTeam.count)
+	assert.Equal(sirius.ValidationErrors{
+		"destination": {"": "Select a team to move members to"},
+	}, template.lastVars.(moveTeamMembersVars).Errors)
+}
+
+func TestPostMoveTeamMembersNoPermission(t *testing.T) {
+	assert := assert.New(t)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/teams/move-members/123", nil)
+
+	err := moveTeamMembers(nil, nil)(sirius.PermissionSet{}, w, r)
+	assert.Equal(StatusError(http.StatusForbidden), err)
+}
+
+func TestPostMoveTeamMembersBadPath(t *testing.T) {
+	for name, path := range map[string]string{
+		"empty":       "/teams/move-members/",
+		"non-numeric": "/teams/move-members/hello",
+		"suffixed":    "/teams/move-members/123/no",
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			client := newMockMoveTeamMembersClient()
+			r, _ := http.NewRequest("POST", path, nil)
+
+			err := moveTeamMembers(client, nil)(client.requiredPermissions(), nil, r)
+			assert.Equal(StatusError(http.StatusNotFound), err)
+		})
+	}
+}
+
+func TestMoveTeamMembersBadMethod(t *testing.T) {
+	assert := assert.New(t)
+
+	client := newMockMoveTeamMembersClient()
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/teams/move-members/123", nil)
+
+	err := moveTeamMembers(client, nil)(client.requiredPermissions(), w, r)
+	assert.Equal(StatusError(http.StatusMethodNotAllowed), err)
+}
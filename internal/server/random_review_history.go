@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"encoding/csv"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ministryofjustice/opg-go-common/telemetry"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/randomreview"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+type RandomReviewStore interface {
+	RecordChange(context.Context, randomreview.Change) error
+	Changes(context.Context) ([]randomreview.Change, error)
+	Propose(context.Context, randomreview.Proposal) (randomreview.Proposal, error)
+	Proposals(context.Context) ([]randomreview.Proposal, error)
+	Proposal(ctx context.Context, id int) (randomreview.Proposal, error)
+	ClaimProposal(ctx context.Context, id int, from randomreview.Status) error
+	ReleaseProposal(ctx context.Context, id int, to randomreview.Status) error
+	DecideProposal(ctx context.Context, id int, status randomreview.Status, by randomreview.User, at time.Time, comment string) error
+}
+
+type RandomReviewChangeClient interface {
+	MyDetails(sirius.Context) (sirius.MyDetails, error)
+}
+
+// recordRandomReviewChange adds a change that has been made in Sirius to the
+// history. Failures are logged rather than returned, as the settings have
+// already been changed.
+func recordRandomReviewChange(r *http.Request, store RandomReviewStore, change randomreview.Change) {
+	if err := store.RecordChange(r.Context(), change); err != nil {
+		telemetry.LoggerFromContext(r.Context()).Error("could not record random review change", slog.Any("err", err.Error()))
+	}
+}
+
+func getRandomReviewUser(ctx sirius.Context, client RandomReviewChangeClient) (randomreview.User, error) {
+	myDetails, err := client.MyDetails(ctx)
+	if err != nil {
+		return randomreview.User{}, err
+	}
+
+	return randomreview.User{
+		ID:    myDetails.ID,
+		Name:  myDetails.Firstname + " " + myDetails.Surname,
+		Email: myDetails.Email,
+	}, nil
+}
+
+func exportRandomReviewHistory(store RandomReviewStore) Handler {
+	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		if !perm.HasPermission("v1-random-review-settings", http.MethodGet) {
+			return StatusError(http.StatusForbidden)
+		}
+
+		if r.Method != http.MethodGet {
+			return StatusError(http.StatusMethodNotAllowed)
+		}
+
+		changes, err := store.Changes(r.Context())
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="random-review-history.csv"`)
+
+		out := csv.NewWriter(w)
+		_ = out.Write([]string{
+			"Time", "Changed by", "Changed by email", "Approved by", "Approved by email", "Reason",
+			"Old lay percentage", "New lay percentage",
+			"Old PA percentage", "New PA percentage",
+			"Old pro percentage", "New pro percentage",
+			"Old review cycle", "New review cycle",
+		})
+
+		for _, change := range changes {
+			_ = out.Write(spreadsheetSafe([]string{
+				change.Time.Format(time.RFC3339), change.By.Name, change.By.Email, change.ApprovedBy.Name, change.ApprovedBy.Email, change.Reason,
+				strconv.Itoa(change.Before.LayPercentage), strconv.Itoa(change.After.LayPercentage),
+				strconv.Itoa(change.Before.PaPercentage), strconv.Itoa(change.After.PaPercentage),
+				strconv.Itoa(change.Before.ProPercentage), strconv.Itoa(change.After.ProPercentage),
+				strconv.Itoa(change.Before.ReviewCycle), strconv.Itoa(change.After.ReviewCycle),
+			}))
+		}
+
+		out.Flush()
+		return out.Error()
+	}
+}
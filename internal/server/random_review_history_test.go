@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/randomreview"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockRandomReviewStore struct {
+	changes    []randomreview.Change
+	proposals  []randomreview.Proposal
+	claimed    []int
+	released   []int
+	decided    map[int]randomreview.Status
+	claimErr   error
+	releaseErr error
+	decideErr  error
+	err        error
+}
+
+func (m *mockRandomReviewStore) RecordChange(ctx context.Context, change randomreview.Change) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	m.changes = append(m.changes, change)
+	return nil
+}
+
+func (m *mockRandomReviewStore) Changes(ctx context.Context) ([]randomreview.Change, error) {
+	return m.changes, m.err
+}
+
+func (m *mockRandomReviewStore) Propose(ctx context.Context, proposal randomreview.Proposal) (randomreview.Proposal, error) {
+	if m.err != nil {
+		return proposal, m.err
+	}
+
+	proposal.ID = len(m.proposals) + 1
+	proposal.Status = randomreview.StatusPending
+	m.proposals = append(m.proposals, proposal)
+	return proposal, nil
+}
+
+func (m *mockRandomReviewStore) Proposals(ctx context.Context) ([]randomreview.Proposal, error) {
+	return m.proposals, m.err
+}
+
+func (m *mockRandomReviewStore) Proposal(ctx context.Context, id int) (randomreview.Proposal, error) {
+	for _, proposal := range m.proposals {
+		if proposal.ID == id {
+			return proposal, nil
+		}
+	}
+
+	return randomreview.Proposal{}, randomreview.ErrNotFound
+}
+
+func (m *mockRandomReviewStore) ClaimProposal(ctx context.Context, id int, from randomreview.Status) error {
+	if m.claimErr != nil {
+		return m.claimErr
+	}
+
+	m.claimed = append(m.claimed, id)
+	return m.err
+}
+
+func (m *mockRandomReviewStore) ReleaseProposal(ctx context.Context, id int, to randomreview.Status) error {
+	m.released = append(m.released, id)
+	if m.releaseErr != nil {
+		return m.releaseErr
+	}
+
+	return m.err
+}
+
+func (m *mockRandomReviewStore) DecideProposal(ctx context.Context, id int, status randomreview.Status, by randomreview.User, at time.Time, comment string) error {
+	if m.decideErr != nil {
+		return m.decideErr
+	}
+
+	if m.err != nil {
+		return m.err
+	}
+
+	if m.decided == nil {
+		m.decided = map[int]randomreview.Status{}
+	}
+	m.decided[id] = status
+	return nil
+}
+
+func TestExportRandomReviewHistory(t *testing.T) {
+	assert := assert.New(t)
+
+	store := &mockRandomReviewStore{
+		changes: []randomreview.Change{
+			{
+				ID:         2,
+				Time:       time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC),
+				By:         randomreview.User{ID: 1, Name: "Anne Able", Email: "anne@example.com"},
+				ApprovedBy: randomreview.User{ID: 2, Name: "Bob Bell", Email: "bob@example.com"},
+				Before:     sirius.RandomReviews{LayPercentage: 10, PaPercentage: 20, ProPercentage: 30, ReviewCycle: 1},
+				After:      sirius.RandomReviews{LayPercentage: 15, PaPercentage: 20, ProPercentage: 30, ReviewCycle: 1},
+				Reason:     "Audit recommendation, March",
+			},
+			{
+				ID:     1,
+				Time:   time.Date(2021, 1, 3, 4, 5, 6, 0, time.UTC),
+				Before: sirius.RandomReviews{LayPercentage: 10, PaPercentage: 20, ProPercentage: 30, ReviewCycle: 2},
+				After:  sirius.RandomReviews{LayPercentage: 10, PaPercentage: 20, ProPercentage: 30, ReviewCycle: 1},
+				Reason: "=HYPERLINK(\"http://example.com\")",
+			},
+		},
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/random-reviews/history.csv", nil)
+
+	err := exportRandomReviewHistory(store)(sirius.PermissionSet{"v1-random-review-settings": sirius.PermissionGroup{Permissions: []string{"get"}}}, w, r)
+	assert.Nil(err)
+
+	resp := w.Result()
+	assert.Equal("text/csv", resp.Header.Get("Content-Type"))
+	assert.Equal(`attachment; filename="random-review-history.csv"`, resp.Header.Get("Content-Disposition"))
+	assert.Equal(`Time,Changed by,Changed by email,Approved by,Approved by email,Reason,Old lay percentage,New lay percentage,Old PA percentage,New PA percentage,Old pro percentage,New pro percentage,Old review cycle,New review cycle
+2021-02-03T04:05:06Z,Anne Able,anne@example.com,Bob Bell,bob@example.com,"Audit recommendation, March",10,15,20,20,30,30,1,1
+2021-01-03T04:05:06Z,,,,,"'=HYPERLINK(""http://example.com"")",10,10,20,20,30,30,2,1
+`, w.Body.String())
+}
+
+func TestExportRandomReviewHistoryNoPermission(t *testing.T) {
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/random-reviews/history.csv", nil)
+
+	err := exportRandomReviewHistory(nil)(sirius.PermissionSet{}, w, r)
+	assert.Equal(t, StatusError(http.StatusForbidden), err)
+}
+
+func TestExportRandomReviewHistoryError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedErr := errors.New("err")
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/random-reviews/history.csv", nil)
+
+	err := exportRandomReviewHistory(&mockRandomReviewStore{err: expectedErr})(sirius.PermissionSet{"v1-random-review-settings": sirius.PermissionGroup{Permissions: []string{"get"}}}, w, r)
+	assert.Equal(expectedErr, err)
+	assert.Equal("", w.Body.String())
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ministryofjustice/opg-go-common/telemetry"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/randomreview"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+type ReviewRandomReviewProposalClient interface {
+	RandomReviews(sirius.Context) (sirius.RandomReviews, error)
+	EditRandomReviewSettings(sirius.Context, sirius.EditRandomReview) error
+	RandomReviewChangeClient
+}
+
+type reviewRandomReviewProposalVars struct {
+	Path            string
+	XSRFToken       string
+	Proposal        randomReviewProposal
+	Current         sirius.RandomReviews
+	After           sirius.RandomReviews
+	Population      randomreview.Population
+	CurrentEstimate randomreview.Estimate
+	NewEstimate     *randomreview.Estimate
+	OwnChange       bool
+	Due             bool
+	CanCancel       bool
+	Comment         string
+	Errors          sirius.ValidationErrors
+}
+
+// reviewRandomReviewProposal lets a proposed change be approved or rejected.
+// Proposals must be approved by someone other than the person who made them.
+// Approving a proposal makes it in Sirius, unless it is scheduled to take
+// effect later, in which case the Scheduler makes it once it is due.
+//
+// A proposal can be cancelled by the person who made it while it is pending,
+// and by anyone once it has been approved but not yet made.
+func reviewRandomReviewProposal(client ReviewRandomReviewProposalClient, store RandomReviewStore, population randomreview.Population, tmpl Template) Handler {
+	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		if !perm.HasPermission("v1-random-review-settings", http.MethodPost) {
+			return StatusError(http.StatusForbidden)
+		}
+
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			return StatusError(http.StatusMethodNotAllowed)
+		}
+
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/random-reviews/proposals/"))
+		if err != nil {
+			return StatusError(http.StatusNotFound)
+		}
+
+		ctx := getContext(r)
+
+		proposal, err := store.Proposal(r.Context(), id)
+		if errors.Is(err, randomreview.ErrNotFound) {
+			return StatusError(http.StatusNotFound)
+		} else if err != nil {
+			return err
+		}
+
+		current, err := client.RandomReviews(ctx)
+		if err != nil {
+			return err
+		}
+
+		user, err := getRandomReviewUser(ctx, client)
+		if err != nil {
+			return err
+		}
+
+		edit := randomreview.FillEdit(current, proposal.Settings)
+		after := randomreview.EditedSettings(edit)
+		newEstimate := randomreview.EstimateReviews(population, after)
+
+		vars := reviewRandomReviewProposalVars{
+			Path:            r.URL.Path,
+			XSRFToken:       ctx.XSRFToken,
+			Proposal:        randomReviewProposal{proposal},
+			Current:         current,
+			After:           after,
+			Population:      population,
+			CurrentEstimate: randomreview.EstimateReviews(population, current),
+			NewEstimate:     &newEstimate,
+			OwnChange:       user.ID == proposal.ProposedBy.ID,
+			Due:             proposal.Due(time.Now()),
+		}
+		vars.CanCancel = proposal.Status == randomreview.StatusApproved ||
+			(proposal.Status == randomreview.StatusPending && vars.OwnChange)
+
+		if r.Method == http.MethodGet {
+			return tmpl.ExecuteTemplate(w, "page", vars)
+		}
+
+		if proposal.Status != randomreview.StatusPending && proposal.Status != randomreview.StatusApproved {
+			return RedirectError("/random-reviews")
+		}
+
+		vars.Comment = strings.TrimSpace(r.PostFormValue("comment"))
+		target := fmt.Sprintf("random-review-proposal:%d", proposal.ID)
+
+		switch r.PostFormValue("action") {
+		case "approve":
+			if proposal.Status != randomreview.StatusPending {
+				return RedirectError("/random-reviews")
+			}
+
+			if vars.OwnChange {
+				vars.Errors = sirius.ValidationErrors{
+					"#": {"": "A change must be approved by someone other than the person who proposed it"},
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				return tmpl.ExecuteTemplate(w, "page", vars)
+			}
+
+			if !vars.Due {
+				err := store.DecideProposal(r.Context(), proposal.ID, randomreview.StatusApproved, user, time.Now().UTC(), vars.Comment)
+				recordAudit(r, "approve-random-review-proposal", target, proposal, nil, err)
+				if err != nil && !errors.Is(err, randomreview.ErrNotPending) {
+					return err
+				}
+
+				return RedirectError("/random-reviews")
+			}
+
+			// claim the proposal so that it cannot be approved twice, or
+			// rejected, while the change is being made
+			if err := store.ClaimProposal(r.Context(), proposal.ID, randomreview.StatusPending); errors.Is(err, randomreview.ErrNotPending) {
+				return RedirectError("/random-reviews")
+			} else if err != nil {
+				return err
+			}
+
+			err := client.EditRandomReviewSettings(ctx, edit)
+			recordAudit(r, "edit-random-review-settings", "random-review-settings", current, edit, err)
+
+			if err != nil {
+				if releaseErr := store.ReleaseProposal(r.Context(), proposal.ID, randomreview.StatusPending); releaseErr != nil {
+					return errors.Join(err, releaseErr)
+				}
+			}
+
+			if verr, ok := err.(sirius.ValidationError); ok {
+				vars.Errors = verr.Errors
+				if len(vars.Errors) == 0 {
+					vars.Errors = sirius.ValidationErrors{"#": {"": verr.Message}}
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				return tmpl.ExecuteTemplate(w, "page", vars)
+			} else if err != nil {
+				return err
+			}
+
+			now := time.Now().UTC()
+			err = store.DecideProposal(r.Context(), proposal.ID, randomreview.StatusApplied, user, now, vars.Comment)
+			recordAudit(r, "approve-random-review-proposal", target, proposal, nil, err)
+			if err != nil {
+				// the settings have already been changed, so the change must
+				// still be recorded
+				telemetry.LoggerFromContext(r.Context()).Error("could not mark random review proposal as applied", slog.Any("err", err.Error()), slog.Int("id", proposal.ID))
+			}
+
+			recordRandomReviewChange(r, store, randomreview.Change{
+				Time:       now,
+				By:         proposal.ProposedBy,
+				ApprovedBy: user,
+				Before:     current,
+				After:      vars.After,
+				Reason:     proposal.Reason,
+			})
+
+		case "reject":
+			if proposal.Status != randomreview.StatusPending {
+				return RedirectError("/random-reviews")
+			}
+
+			if vars.Comment == "" {
+				vars.Errors = sirius.ValidationErrors{
+					"comment": {"": "Enter why the change is being rejected"},
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				return tmpl.ExecuteTemplate(w, "page", vars)
+			}
+
+			err := store.DecideProposal(r.Context(), proposal.ID, randomreview.StatusRejected, user, time.Now().UTC(), vars.Comment)
+			recordAudit(r, "reject-random-review-proposal", target, proposal, nil, err)
+			if err != nil && !errors.Is(err, randomreview.ErrNotPending) {
+				return err
+			}
+
+		case "cancel":
+			if !vars.CanCancel {
+				return StatusError(http.StatusForbidden)
+			}
+
+			err := store.DecideProposal(r.Context(), proposal.ID, randomreview.StatusCancelled, user, time.Now().UTC(), vars.Comment)
+			recordAudit(r, "cancel-random-review-proposal", target, proposal, nil, err)
+			if err != nil && !errors.Is(err, randomreview.ErrNotPending) {
+				return err
+			}
+
+		default:
+			return StatusError(http.StatusBadRequest)
+		}
+
+		return RedirectError("/random-reviews")
+	}
+}
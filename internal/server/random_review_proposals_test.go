@@ -0,0 +1,537 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/randomreview"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockReviewRandomReviewProposalClient struct {
+	count     int
+	saveCount int
+	lastCtx   sirius.Context
+	lastEdit  sirius.EditRandomReview
+	userID    int
+	err       error
+	saveErr   error
+	data      sirius.RandomReviews
+}
+
+func (m *mockReviewRandomReviewProposalClient) RandomReviews(ctx sirius.Context) (sirius.RandomReviews, error) {
+	m.count += 1
+	m.lastCtx = ctx
+
+	return m.data, m.err
+}
+
+func (m *mockReviewRandomReviewProposalClient) EditRandomReviewSettings(ctx sirius.Context, edit sirius.EditRandomReview) error {
+	m.saveCount += 1
+	m.lastCtx = ctx
+	m.lastEdit = edit
+
+	return m.saveErr
+}
+
+func (m *mockReviewRandomReviewProposalClient) MyDetails(ctx sirius.Context) (sirius.MyDetails, error) {
+	return sirius.MyDetails{ID: m.userID, Firstname: "Bob", Surname: "Brown", Email: "bob@example.com"}, nil
+}
+
+func (m *mockReviewRandomReviewProposalClient) requiredPermissions() sirius.PermissionSet {
+	return sirius.PermissionSet{"v1-random-review-settings": sirius.PermissionGroup{Permissions: []string{"post"}}}
+}
+
+func testRandomReviewProposalStore() *mockRandomReviewStore {
+	return &mockRandomReviewStore{
+		proposals: []randomreview.Proposal{{
+			ID:         3,
+			ProposedBy: randomreview.User{ID: 12, Name: "Anne Able", Email: "anne@example.com"},
+			Settings:   sirius.EditRandomReview{LayPercentage: "25"},
+			Reason:     "Audit recommendation",
+			Status:     randomreview.StatusPending,
+		}},
+	}
+}
+
+func TestGetReviewRandomReviewProposal(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockReviewRandomReviewProposalClient{userID: 13, data: sirius.RandomReviews{LayPercentage: 10, PaPercentage: 20, ProPercentage: 30, ReviewCycle: 1}}
+	store := testRandomReviewProposalStore()
+	population := randomreview.Population{Lay: 1000, PA: 100, Pro: 10}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/random-reviews/proposals/3", nil)
+
+	err := reviewRandomReviewProposal(client, store, population, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(getContext(r), client.lastCtx)
+	assert.Equal(0, client.saveCount)
+	assert.Equal(1, template.count)
+	assert.Equal("page", template.lastName)
+	assert.Equal(reviewRandomReviewProposalVars{
+		Path:            "/random-reviews/proposals/3",
+		Proposal:        randomReviewProposal{store.proposals[0]},
+		Current:         client.data,
+		After:           sirius.RandomReviews{LayPercentage: 25, PaPercentage: 20, ProPercentage: 30, ReviewCycle: 1},
+		Population:      population,
+		CurrentEstimate: randomreview.Estimate{Lay: 100, PA: 20, Pro: 3, Total: 123, ReviewCycle: 1},
+		NewEstimate:     &randomreview.Estimate{Lay: 250, PA: 20, Pro: 3, Total: 273, ReviewCycle: 1},
+		Due:             true,
+	}, template.lastVars)
+}
+
+func TestPostReviewRandomReviewProposalApprove(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockReviewRandomReviewProposalClient{userID: 13, data: sirius.RandomReviews{LayPercentage: 10, PaPercentage: 20, ProPercentage: 30, ReviewCycle: 1}}
+	store := testRandomReviewProposalStore()
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/random-reviews/proposals/3", strings.NewReader("action=approve"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := reviewRandomReviewProposal(client, store, randomreview.Population{}, template)(client.requiredPermissions(), w, r)
+	assert.Equal(RedirectError("/random-reviews"), err)
+	assert.Equal(0, template.count)
+
+	assert.Equal(1, client.saveCount)
+	assert.Equal(sirius.EditRandomReview{LayPercentage: "25", PaPercentage: "20", ProPercentage: "30", ReviewCycle: "1"}, client.lastEdit)
+	assert.Equal([]int{3}, store.claimed)
+	assert.Empty(store.released)
+	assert.Equal(map[int]randomreview.Status{3: randomreview.StatusApplied}, store.decided)
+
+	assert.Len(store.changes, 1)
+	change := store.changes[0]
+	assert.Equal(randomreview.User{ID: 12, Name: "Anne Able", Email: "anne@example.com"}, change.By)
+	assert.Equal(randomreview.User{ID: 13, Name: "Bob Brown", Email: "bob@example.com"}, change.ApprovedBy)
+	assert.Equal(client.data, change.Before)
+	assert.Equal(25, change.After.LayPercentage)
+	assert.Equal("Audit recommendation", change.Reason)
+}
+
+func TestPostReviewRandomReviewProposalApproveOwnChange(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockReviewRandomReviewProposalClient{userID: 12}
+	store := testRandomReviewProposalStore()
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/random-reviews/proposals/3", strings.NewReader("action=approve"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := reviewRandomReviewProposal(client, store, randomreview.Population{}, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(http.StatusBadRequest, w.Result().StatusCode)
+	assert.Equal(0, client.saveCount)
+	assert.Nil(store.decided)
+
+	vars := template.lastVars.(reviewRandomReviewProposalVars)
+	assert.True(vars.OwnChange)
+	assert.Contains(vars.Errors, "#")
+}
+
+func TestPostReviewRandomReviewProposalApproveBeforeEffective(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockReviewRandomReviewProposalClient{userID: 13}
+	store := testRandomReviewProposalStore()
+	store.proposals[0].EffectiveAt = time.Date(2099, 7, 1, 8, 30, 0, 0, time.UTC)
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/random-reviews/proposals/3", strings.NewReader("action=approve"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := reviewRandomReviewProposal(client, store, randomreview.Population{}, template)(client.requiredPermissions(), w, r)
+	assert.Equal(RedirectError("/random-reviews"), err)
+
+	assert.Equal(0, client.saveCount)
+	assert.Empty(store.claimed)
+	assert.Equal(map[int]randomreview.Status{3: randomreview.StatusApproved}, store.decided)
+	assert.Empty(store.changes)
+}
+
+func TestPostReviewRandomReviewProposalApproveValidationError(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockReviewRandomReviewProposalClient{
+		userID:  13,
+		saveErr: sirius.ValidationError{Errors: sirius.ValidationErrors{"layPercentage": {"between": "Must be between 0 and 100"}}},
+	}
+	store := testRandomReviewProposalStore()
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/random-reviews/proposals/3", strings.NewReader("action=approve"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := reviewRandomReviewProposal(client, store, randomreview.Population{}, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(http.StatusBadRequest, w.Result().StatusCode)
+	assert.Equal(sirius.ValidationErrors{"layPercentage": {"between": "Must be between 0 and 100"}}, template.lastVars.(reviewRandomReviewProposalVars).Errors)
+	assert.Equal([]int{3}, store.released)
+	assert.Nil(store.decided)
+	assert.Empty(store.changes)
+}
+
+func TestPostReviewRandomReviewProposalApproveError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedErr := errors.New("err")
+	client := &mockReviewRandomReviewProposalClient{userID: 13, saveErr: expectedErr}
+	store := testRandomReviewProposalStore()
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/random-reviews/proposals/3", strings.NewReader("action=approve"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := reviewRandomReviewProposal(client, store, randomreview.Population{}, &mockTemplate{})(client.requiredPermissions(), w, r)
+	assert.Equal(expectedErr, err)
+
+	assert.Equal([]int{3}, store.claimed)
+	assert.Equal([]int{3}, store.released)
+	assert.Nil(store.decided)
+}
+
+func TestPostReviewRandomReviewProposalApproveErrorNotReleased(t *testing.T) {
+	assert := assert.New(t)
+
+	saveErr := errors.New("save")
+	releaseErr := errors.New("release")
+	client := &mockReviewRandomReviewProposalClient{userID: 13, saveErr: saveErr}
+	store := testRandomReviewProposalStore()
+	store.releaseErr = releaseErr
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/random-reviews/proposals/3", strings.NewReader("action=approve"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := reviewRandomReviewProposal(client, store, randomreview.Population{}, &mockTemplate{})(client.requiredPermissions(), w, r)
+	assert.ErrorIs(err, saveErr)
+	assert.ErrorIs(err, releaseErr)
+
+	assert.Equal([]int{3}, store.released)
+	assert.Nil(store.decided)
+}
+
+func TestPostReviewRandomReviewProposalApproveNotDecided(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockReviewRandomReviewProposalClient{userID: 13, data: sirius.RandomReviews{LayPercentage: 10, PaPercentage: 20, ProPercentage: 30, ReviewCycle: 1}}
+	store := testRandomReviewProposalStore()
+	store.decideErr = errors.New("write failed")
+
+	ctx, logs := contextWithLogger()
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequestWithContext(ctx, "POST", "/random-reviews/proposals/3", strings.NewReader("action=approve"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := reviewRandomReviewProposal(client, store, randomreview.Population{}, &mockTemplate{})(client.requiredPermissions(), w, r)
+	assert.Equal(RedirectError("/random-reviews"), err)
+
+	assert.Equal(1, client.saveCount)
+	assert.Len(store.changes, 1)
+	assert.Equal(25, store.changes[0].After.LayPercentage)
+	assert.Contains(logs.String(), "could not mark random review proposal as applied")
+	assert.Contains(logs.String(), "write failed")
+}
+
+func TestPostReviewRandomReviewProposalApproveAlreadyClaimed(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockReviewRandomReviewProposalClient{userID: 13}
+	store := testRandomReviewProposalStore()
+	store.claimErr = randomreview.ErrNotPending
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/random-reviews/proposals/3", strings.NewReader("action=approve"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := reviewRandomReviewProposal(client, store, randomreview.Population{}, &mockTemplate{})(client.requiredPermissions(), w, r)
+	assert.Equal(RedirectError("/random-reviews"), err)
+
+	assert.Equal(0, client.saveCount)
+	assert.Empty(store.released)
+	assert.Nil(store.decided)
+	assert.Empty(store.changes)
+}
+
+func TestPostReviewRandomReviewProposalReject(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockReviewRandomReviewProposalClient{userID: 12}
+	store := testRandomReviewProposalStore()
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/random-reviews/proposals/3", strings.NewReader("action=reject&comment=Not+agreed"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := reviewRandomReviewProposal(client, store, randomreview.Population{}, template)(client.requiredPermissions(), w, r)
+	assert.Equal(RedirectError("/random-reviews"), err)
+
+	assert.Equal(0, client.saveCount)
+	assert.Equal(map[int]randomreview.Status{3: randomreview.StatusRejected}, store.decided)
+	assert.Empty(store.changes)
+}
+
+func TestPostReviewRandomReviewProposalRejectRequiresComment(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockReviewRandomReviewProposalClient{userID: 13}
+	store := testRandomReviewProposalStore()
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/random-reviews/proposals/3", strings.NewReader("action=reject&comment=+"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := reviewRandomReviewProposal(client, store, randomreview.Population{}, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(http.StatusBadRequest, w.Result().StatusCode)
+	assert.Equal(sirius.ValidationErrors{"comment": {"": "Enter why the change is being rejected"}}, template.lastVars.(reviewRandomReviewProposalVars).Errors)
+	assert.Nil(store.decided)
+}
+
+func TestPostReviewRandomReviewProposalCancel(t *testing.T) {
+	testCases := map[string]struct {
+		userID   int
+		status   randomreview.Status
+		expected error
+		decided  map[int]randomreview.Status
+	}{
+		"own pending": {
+			userID:   12,
+			status:   randomreview.StatusPending,
+			expected: RedirectError("/random-reviews"),
+			decided:  map[int]randomreview.Status{3: randomreview.StatusCancelled},
+		},
+		"other pending": {
+			userID:   13,
+			status:   randomreview.StatusPending,
+			expected: StatusError(http.StatusForbidden),
+		},
+		"approved": {
+			userID:   14,
+			status:   randomreview.StatusApproved,
+			expected: RedirectError("/random-reviews"),
+			decided:  map[int]randomreview.Status{3: randomreview.StatusCancelled},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client := &mockReviewRandomReviewProposalClient{userID: tc.userID}
+			store := testRandomReviewProposalStore()
+			store.proposals[0].Status = tc.status
+
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest("POST", "/random-reviews/proposals/3", strings.NewReader("action=cancel"))
+			r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+			err := reviewRandomReviewProposal(client, store, randomreview.Population{}, &mockTemplate{})(client.requiredPermissions(), w, r)
+			assert.Equal(t, tc.expected, err)
+			assert.Equal(t, tc.decided, store.decided)
+			assert.Equal(t, 0, client.saveCount)
+		})
+	}
+}
+
+func TestPostReviewRandomReviewProposalApproved(t *testing.T) {
+	for _, action := range []string{"approve", "reject"} {
+		t.Run(action, func(t *testing.T) {
+			client := &mockReviewRandomReviewProposalClient{userID: 13}
+			store := testRandomReviewProposalStore()
+			store.proposals[0].Status = randomreview.StatusApproved
+
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest("POST", "/random-reviews/proposals/3", strings.NewReader("action="+action+"&comment=No"))
+			r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+			err := reviewRandomReviewProposal(client, store, randomreview.Population{}, &mockTemplate{})(client.requiredPermissions(), w, r)
+			assert.Equal(t, RedirectError("/random-reviews"), err)
+			assert.Nil(t, store.decided)
+			assert.Equal(t, 0, client.saveCount)
+		})
+	}
+}
+
+func TestPostReviewRandomReviewProposalNotPending(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockReviewRandomReviewProposalClient{userID: 13}
+	store := testRandomReviewProposalStore()
+	store.proposals[0].Status = randomreview.StatusRejected
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/random-reviews/proposals/3", strings.NewReader("action=approve"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := reviewRandomReviewProposal(client, store, randomreview.Population{}, template)(client.requiredPermissions(), w, r)
+	assert.Equal(RedirectError("/random-reviews"), err)
+	assert.Equal(0, client.saveCount)
+	assert.Nil(store.decided)
+}
+
+func TestReviewRandomReviewProposalErrors(t *testing.T) {
+	testCases := map[string]struct {
+		method    string
+		path      string
+		clientErr error
+		expected  error
+	}{
+		"bad id": {
+			method:   "GET",
+			path:     "/random-reviews/proposals/x",
+			expected: StatusError(http.StatusNotFound),
+		},
+		"not found": {
+			method:   "GET",
+			path:     "/random-reviews/proposals/99",
+			expected: StatusError(http.StatusNotFound),
+		},
+		"method": {
+			method:   "PUT",
+			path:     "/random-reviews/proposals/3",
+			expected: StatusError(http.StatusMethodNotAllowed),
+		},
+		"unknown action": {
+			method:   "POST",
+			path:     "/random-reviews/proposals/3",
+			expected: StatusError(http.StatusBadRequest),
+		},
+		"client": {
+			method:    "GET",
+			path:      "/random-reviews/proposals/3",
+			clientErr: errors.New("err"),
+			expected:  errors.New("err"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client := &mockReviewRandomReviewProposalClient{userID: 13, err: tc.clientErr}
+
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest(tc.method, tc.path, nil)
+
+			err := reviewRandomReviewProposal(client, testRandomReviewProposalStore(), randomreview.Population{}, &mockTemplate{})(client.requiredPermissions(), w, r)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+}
+
+func TestReviewRandomReviewProposalNoPermission(t *testing.T) {
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/random-reviews/proposals/3", nil)
+
+	err := reviewRandomReviewProposal(nil, nil, randomreview.Population{}, nil)(sirius.PermissionSet{}, w, r)
+	assert.Equal(t, StatusError(http.StatusForbidden), err)
+}
+
+type mockFourEyesClient struct {
+	Client
+	userID    int
+	saveCount int
+}
+
+func (m *mockFourEyesClient) MyPermissions(ctx sirius.Context) (sirius.PermissionSet, error) {
+	return sirius.PermissionSet{"v1-random-review-settings": sirius.PermissionGroup{Permissions: []string{"get", "post"}}}, nil
+}
+
+func (m *mockFourEyesClient) MyDetails(ctx sirius.Context) (sirius.MyDetails, error) {
+	return sirius.MyDetails{ID: m.userID}, nil
+}
+
+func (m *mockFourEyesClient) RandomReviews(ctx sirius.Context) (sirius.RandomReviews, error) {
+	return sirius.RandomReviews{LayPercentage: 10, PaPercentage: 20, ProPercentage: 30, ReviewCycle: 1}, nil
+}
+
+func (m *mockFourEyesClient) EditRandomReviewSettings(ctx sirius.Context, edit sirius.EditRandomReview) error {
+	m.saveCount += 1
+	return nil
+}
+
+func TestRandomReviewSettingsCannotBeChangedByOnePerson(t *testing.T) {
+	assert := assert.New(t)
+
+	files, _ := filepath.Glob("../../web/template/*.gotmpl")
+	templates := map[string]*template.Template{}
+	for _, file := range files {
+		templates[filepath.Base(file)] = template.Must(template.New("").Parse(`{{ define "page" }}{{ end }}`))
+	}
+
+	client := &mockFourEyesClient{userID: 12}
+	store, _ := randomreview.NewStore("")
+	mux := routes(client, &templateLookup{templates: templates}, Options{ReviewStore: store})
+
+	post := func(path, form string) {
+		r, _ := http.NewRequest("POST", path, strings.NewReader(form))
+		r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		mux.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	post("/random-reviews/edit", "layPercentage=50&paPercentage=20&proPercentage=30&reviewCycle=1&reason=Now")
+	post("/random-reviews/schedule", "layPercentage=60&effectiveDate=2000-01-01&effectiveTime=00:00&reason=Past")
+	post("/random-reviews/schedule", "layPercentage=60&effectiveDate=2099-01-01&effectiveTime=00:00&reason=Later")
+
+	proposals, _ := store.Proposals(t.Context())
+	assert.Len(proposals, 2)
+
+	for _, proposal := range proposals {
+		path := "/random-reviews/proposals/" + strconv.Itoa(proposal.ID)
+		post(path, "action=approve")
+		post(path, "action=approve&comment=Fine")
+	}
+
+	for _, path := range []string{"/random-reviews", "/api/random-reviews", "/random-reviews/history.csv"} {
+		post(path, "layPercentage=50&action=approve")
+	}
+
+	scheduler := randomreview.NewScheduler(slog.New(slog.NewTextHandler(io.Discard, nil)), client, store, func(ctx context.Context) sirius.Context {
+		return sirius.Context{Context: ctx}
+	}, time.Minute)
+	scheduler.ApplyDue(t.Context())
+
+	assert.Equal(0, client.saveCount)
+
+	changes, _ := store.Changes(t.Context())
+	assert.Empty(changes)
+
+	proposals, _ = store.Proposals(t.Context())
+	for _, proposal := range proposals {
+		assert.Equal(randomreview.StatusPending, proposal.Status)
+	}
+
+	client.userID = 13
+	post("/random-reviews/proposals/"+strconv.Itoa(proposals[1].ID), "action=approve")
+	assert.Equal(1, client.saveCount)
+
+	post("/random-reviews/proposals/"+strconv.Itoa(proposals[0].ID), "action=approve")
+	scheduler.ApplyDue(t.Context())
+	assert.Equal(1, client.saveCount)
+
+	later, _ := store.Proposal(t.Context(), proposals[0].ID)
+	assert.Equal(randomreview.StatusApproved, later.Status)
+}
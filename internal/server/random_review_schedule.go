@@ -0,0 +1,125 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	_ "time/tzdata" // so that UK time is known in containers without zoneinfo
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/randomreview"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+// scheduleLocation is the time zone that scheduled changes are entered in.
+var scheduleLocation, _ = time.LoadLocation("Europe/London")
+
+type ScheduleRandomReviewClient interface {
+	RandomReviews(sirius.Context) (sirius.RandomReviews, error)
+	RandomReviewChangeClient
+}
+
+type scheduleRandomReviewVars struct {
+	Path          string
+	XSRFToken     string
+	Current       sirius.RandomReviews
+	Settings      sirius.EditRandomReview
+	EffectiveDate string
+	EffectiveTime string
+	Reason        string
+	Errors        sirius.ValidationErrors
+}
+
+// scheduleRandomReview proposes a change to take effect at a later time. Like
+// any other proposal it must be approved by someone else, and the scheduler
+// makes it once it is due.
+func scheduleRandomReview(client ScheduleRandomReviewClient, store RandomReviewStore, tmpl Template) Handler {
+	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		if !perm.HasPermission("v1-random-review-settings", http.MethodPost) {
+			return StatusError(http.StatusForbidden)
+		}
+
+		ctx := getContext(r)
+
+		current, err := client.RandomReviews(ctx)
+		if err != nil {
+			return err
+		}
+
+		vars := scheduleRandomReviewVars{
+			Path:          r.URL.Path,
+			XSRFToken:     ctx.XSRFToken,
+			Current:       current,
+			EffectiveTime: "00:00",
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			return tmpl.ExecuteTemplate(w, "page", vars)
+
+		case http.MethodPost:
+			vars.Settings = editRandomReviewFromForm(r)
+			vars.EffectiveDate = r.PostFormValue("effectiveDate")
+			vars.EffectiveTime = r.PostFormValue("effectiveTime")
+			vars.Reason = strings.TrimSpace(r.PostFormValue("reason"))
+
+			effectiveAt, errs := validateScheduledChange(vars.Settings, vars.EffectiveDate, vars.EffectiveTime, vars.Reason, time.Now())
+			if errs != nil {
+				vars.Errors = errs
+				w.WriteHeader(http.StatusBadRequest)
+				return tmpl.ExecuteTemplate(w, "page", vars)
+			}
+
+			user, err := getRandomReviewUser(ctx, client)
+			if err != nil {
+				return err
+			}
+
+			proposal, err := store.Propose(r.Context(), randomreview.Proposal{
+				CreatedAt:   time.Now().UTC(),
+				ProposedBy:  user,
+				Before:      current,
+				Settings:    vars.Settings,
+				Reason:      vars.Reason,
+				EffectiveAt: effectiveAt.UTC(),
+			})
+			recordAudit(r, "propose-random-review-settings", fmt.Sprintf("random-review-proposal:%d", proposal.ID), current, proposal, err)
+			if err != nil {
+				return err
+			}
+
+			return RedirectError("/random-reviews")
+
+		default:
+			return StatusError(http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// validateScheduledChange checks that at least one valid setting is being
+// changed for a reason, and returns the time the change should be made if it is in the
+// future.
+func validateScheduledChange(settings sirius.EditRandomReview, date, clock, reason string, now time.Time) (time.Time, sirius.ValidationErrors) {
+	errs := validateRandomReviewEdit(settings, false)
+
+	if settings == (sirius.EditRandomReview{}) {
+		errs["#"] = map[string]string{"": "Enter at least one new setting"}
+	}
+
+	effectiveAt, err := time.ParseInLocation("2006-01-02 15:04", date+" "+clock, scheduleLocation)
+	if err != nil {
+		errs["effectiveDate"] = map[string]string{"": "Enter a valid date and time"}
+	} else if !effectiveAt.After(now) {
+		errs["effectiveDate"] = map[string]string{"": "Date and time must be in the future"}
+	}
+
+	if reason == "" {
+		errs["reason"] = map[string]string{"": "Enter a reason for the change"}
+	}
+
+	if len(errs) > 0 {
+		return time.Time{}, errs
+	}
+
+	return effectiveAt, nil
+}
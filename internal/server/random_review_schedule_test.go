@@ -0,0 +1,155 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/randomreview"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockScheduleRandomReviewClient struct {
+	count   int
+	lastCtx sirius.Context
+	err     error
+	data    sirius.RandomReviews
+}
+
+func (m *mockScheduleRandomReviewClient) RandomReviews(ctx sirius.Context) (sirius.RandomReviews, error) {
+	m.count += 1
+	m.lastCtx = ctx
+
+	return m.data, m.err
+}
+
+func (m *mockScheduleRandomReviewClient) MyDetails(ctx sirius.Context) (sirius.MyDetails, error) {
+	return sirius.MyDetails{ID: 12, Firstname: "Anne", Surname: "Able", Email: "anne@example.com"}, m.err
+}
+
+func (m *mockScheduleRandomReviewClient) requiredPermissions() sirius.PermissionSet {
+	return sirius.PermissionSet{"v1-random-review-settings": sirius.PermissionGroup{Permissions: []string{"post"}}}
+}
+
+func TestGetScheduleRandomReview(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockScheduleRandomReviewClient{data: sirius.RandomReviews{LayPercentage: 10, ReviewCycle: 1}}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/random-reviews/schedule", nil)
+
+	err := scheduleRandomReview(client, &mockRandomReviewStore{}, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(getContext(r), client.lastCtx)
+	assert.Equal(1, template.count)
+	assert.Equal("page", template.lastName)
+	assert.Equal(scheduleRandomReviewVars{
+		Path:          "/random-reviews/schedule",
+		Current:       client.data,
+		EffectiveTime: "00:00",
+	}, template.lastVars)
+}
+
+func TestPostScheduleRandomReview(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockScheduleRandomReviewClient{}
+	store := &mockRandomReviewStore{}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/random-reviews/schedule", strings.NewReader("layPercentage=15&reviewCycle=2&effectiveDate=2099-07-01&effectiveTime=09:30&reason=New+policy"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := scheduleRandomReview(client, store, template)(client.requiredPermissions(), w, r)
+	assert.Equal(RedirectError("/random-reviews"), err)
+	assert.Equal(0, template.count)
+
+	assert.Len(store.proposals, 1)
+	proposal := store.proposals[0]
+	assert.Equal(randomreview.User{ID: 12, Name: "Anne Able", Email: "anne@example.com"}, proposal.ProposedBy)
+	assert.Equal(time.Date(2099, 7, 1, 8, 30, 0, 0, time.UTC), proposal.EffectiveAt)
+	assert.Equal(sirius.EditRandomReview{LayPercentage: "15", ReviewCycle: "2"}, proposal.Settings)
+	assert.Equal(randomreview.StatusPending, proposal.Status)
+	assert.Equal("New policy", proposal.Reason)
+	assert.False(proposal.CreatedAt.IsZero())
+}
+
+func TestPostScheduleRandomReviewValidation(t *testing.T) {
+	testCases := map[string]struct {
+		form   string
+		errors sirius.ValidationErrors
+	}{
+		"no settings": {
+			form:   "effectiveDate=2099-07-01&effectiveTime=09:30&reason=x",
+			errors: sirius.ValidationErrors{"#": {"": "Enter at least one new setting"}},
+		},
+		"no date": {
+			form:   "layPercentage=15&effectiveTime=09:30&reason=x",
+			errors: sirius.ValidationErrors{"effectiveDate": {"": "Enter a valid date and time"}},
+		},
+		"past": {
+			form:   "layPercentage=15&effectiveDate=2000-07-01&effectiveTime=09:30&reason=x",
+			errors: sirius.ValidationErrors{"effectiveDate": {"": "Date and time must be in the future"}},
+		},
+		"invalid setting": {
+			form:   "layPercentage=150&effectiveDate=2099-07-01&effectiveTime=09:30&reason=x",
+			errors: sirius.ValidationErrors{"layPercentage": {"": "Enter a whole number between 0 and 100 for lay cases"}},
+		},
+		"no reason": {
+			form:   "layPercentage=15&effectiveDate=2099-07-01&effectiveTime=09:30",
+			errors: sirius.ValidationErrors{"reason": {"": "Enter a reason for the change"}},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			client := &mockScheduleRandomReviewClient{}
+			store := &mockRandomReviewStore{}
+			template := &mockTemplate{}
+
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest("POST", "/random-reviews/schedule", strings.NewReader(tc.form))
+			r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+			err := scheduleRandomReview(client, store, template)(client.requiredPermissions(), w, r)
+			assert.Nil(err)
+
+			assert.Equal(http.StatusBadRequest, w.Result().StatusCode)
+			assert.Equal(tc.errors, template.lastVars.(scheduleRandomReviewVars).Errors)
+			assert.Empty(store.proposals)
+		})
+	}
+}
+
+func TestScheduleRandomReviewNoPermission(t *testing.T) {
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/random-reviews/schedule", nil)
+
+	err := scheduleRandomReview(nil, nil, nil)(sirius.PermissionSet{}, w, r)
+	assert.Equal(t, StatusError(http.StatusForbidden), err)
+}
+
+func TestScheduleRandomReviewError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedErr := errors.New("err")
+	client := &mockScheduleRandomReviewClient{err: expectedErr}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/random-reviews/schedule", nil)
+
+	err := scheduleRandomReview(client, &mockRandomReviewStore{}, template)(client.requiredPermissions(), w, r)
+	assert.Equal(expectedErr, err)
+	assert.Equal(0, template.count)
+}
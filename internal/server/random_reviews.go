@@ -2,7 +2,9 @@ package server
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/randomreview"
 	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
 )
 
@@ -12,13 +14,27 @@ type RandomReviewsClient interface {
 
 type randomReviewsVars struct {
 	Path          string
+	XSRFToken     string
 	LayPercentage int
 	PaPercentage  int
 	ProPercentage int
 	ReviewCycle   int
+	Changes       []randomreview.Change
+	Proposals     []randomReviewProposal
+	Scheduled     []randomReviewProposal
 }
 
-func randomReviews(client RandomReviewsClient, tmpl Template) Handler {
+type randomReviewProposal struct {
+	randomreview.Proposal
+}
+
+// EffectiveAtLocal is the time the change is made once approved, in the time
+// zone it was entered in.
+func (p randomReviewProposal) EffectiveAtLocal() time.Time {
+	return p.EffectiveAt.In(scheduleLocation)
+}
+
+func randomReviews(client RandomReviewsClient, store RandomReviewStore, tmpl Template) Handler {
 	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
 		if !perm.HasPermission("v1-random-review-settings", http.MethodGet) {
 			return StatusError(http.StatusForbidden)
@@ -35,12 +51,38 @@ func randomReviews(client RandomReviewsClient, tmpl Template) Handler {
 			return err
 		}
 
+		changes, err := store.Changes(r.Context())
+		if err != nil {
+			return err
+		}
+
+		proposals, err := store.Proposals(r.Context())
+		if err != nil {
+			return err
+		}
+
 		vars := randomReviewsVars{
 			Path:          r.URL.Path,
+			XSRFToken:     ctx.XSRFToken,
 			LayPercentage: randomReviews.LayPercentage,
 			PaPercentage:  randomReviews.PaPercentage,
 			ProPercentage: randomReviews.ProPercentage,
 			ReviewCycle:   randomReviews.ReviewCycle,
+			Changes:       changes,
+		}
+
+		// a proposal being approved that already has an approver is a
+		// scheduled change the scheduler is making
+		for _, proposal := range proposals {
+			switch {
+			case proposal.Status == randomreview.StatusPending,
+				proposal.Status == randomreview.StatusApproving && proposal.DecidedBy.ID == 0:
+				vars.Proposals = append(vars.Proposals, randomReviewProposal{proposal})
+			case proposal.Status == randomreview.StatusApproved,
+				proposal.Status == randomreview.StatusApproving,
+				proposal.Status == randomreview.StatusFailed:
+				vars.Scheduled = append(vars.Scheduled, randomReviewProposal{proposal})
+			}
 		}
 
 		return tmpl.ExecuteTemplate(w, "page", vars)
@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/randomreview"
 	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
 	"github.com/stretchr/testify/assert"
 )
@@ -41,7 +43,7 @@ func TestGetRandomReviews(t *testing.T) {
 	w := httptest.NewRecorder()
 	r, _ := http.NewRequest("GET", "/path", nil)
 
-	handler := randomReviews(client, template)
+	handler := randomReviews(client, &mockRandomReviewStore{}, template)
 	err := handler(client.requiredPermissions(), w, r)
 	assert.Nil(err)
 
@@ -67,7 +69,7 @@ func TestGetRandomReviewsUnauthenticated(t *testing.T) {
 	w := httptest.NewRecorder()
 	r, _ := http.NewRequest("GET", "", nil)
 
-	handler := randomReviews(client, template)
+	handler := randomReviews(client, &mockRandomReviewStore{}, template)
 	err := handler(sirius.PermissionSet{}, w, r)
 
 	assert.Equal(StatusError(http.StatusForbidden), err)
@@ -84,7 +86,7 @@ func TestGetRandomReviewsMethodNotAllowed(t *testing.T) {
 	w := httptest.NewRecorder()
 	r, _ := http.NewRequest("POST", "", nil)
 
-	handler := randomReviews(client, template)
+	handler := randomReviews(client, &mockRandomReviewStore{}, template)
 	err := handler(client.requiredPermissions(), w, r)
 
 	assert.Equal(StatusError(http.StatusMethodNotAllowed), err)
@@ -101,10 +103,85 @@ func TestGetRandomReviewsSiriusErrors(t *testing.T) {
 	w := httptest.NewRecorder()
 	r, _ := http.NewRequest("GET", "", nil)
 
-	handler := randomReviews(client, template)
+	handler := randomReviews(client, &mockRandomReviewStore{}, template)
 	err := handler(client.requiredPermissions(), w, r)
 
 	assert.Equal("err", err.Error())
 
 	assert.Equal(0, template.count)
 }
+
+func TestGetRandomReviewsHistory(t *testing.T) {
+	assert := assert.New(t)
+
+	changes := []randomreview.Change{
+		{ID: 1, Reason: "Audit recommendation"},
+	}
+
+	client := &mockRandomReviewsClient{}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/path", nil)
+
+	err := randomReviews(client, &mockRandomReviewStore{changes: changes}, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(changes, template.lastVars.(randomReviewsVars).Changes)
+}
+
+func TestGetRandomReviewsHistoryError(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockRandomReviewsClient{}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/path", nil)
+
+	err := randomReviews(client, &mockRandomReviewStore{err: errors.New("err")}, template)(client.requiredPermissions(), w, r)
+
+	assert.Equal("err", err.Error())
+	assert.Equal(0, template.count)
+}
+
+func TestGetRandomReviewsProposals(t *testing.T) {
+	assert := assert.New(t)
+
+	store := &mockRandomReviewStore{
+		proposals: []randomreview.Proposal{
+			{ID: 1, Status: randomreview.StatusApplied},
+			{ID: 2, Status: randomreview.StatusPending},
+			{ID: 3, Status: randomreview.StatusRejected},
+			{ID: 4, Status: randomreview.StatusPending, EffectiveAt: time.Date(2099, 7, 1, 8, 30, 0, 0, time.UTC)},
+			{ID: 5, Status: randomreview.StatusApproving},
+			{ID: 6, Status: randomreview.StatusApproved, DecidedBy: randomreview.User{ID: 2}},
+			{ID: 7, Status: randomreview.StatusApproving, DecidedBy: randomreview.User{ID: 2}},
+			{ID: 8, Status: randomreview.StatusFailed, DecidedBy: randomreview.User{ID: 2}, Error: "rejected by Sirius"},
+			{ID: 9, Status: randomreview.StatusCancelled},
+		},
+	}
+
+	client := &mockRandomReviewsClient{}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/path", nil)
+
+	err := randomReviews(client, store, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	proposals := template.lastVars.(randomReviewsVars).Proposals
+	assert.Equal([]randomReviewProposal{
+		{store.proposals[1]},
+		{store.proposals[3]},
+		{store.proposals[4]},
+	}, proposals)
+	assert.Equal("1 July 2099 09:30 BST", proposals[1].EffectiveAtLocal().Format("2 January 2006 15:04 MST"))
+
+	assert.Equal([]randomReviewProposal{
+		{store.proposals[5]},
+		{store.proposals[6]},
+		{store.proposals[7]},
+	}, template.lastVars.(randomReviewsVars).Scheduled)
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+)
+
+// TemplateLoader parses the templates, returning those that could be parsed
+// along with any errors.
+type TemplateLoader func() (map[string]*template.Template, error)
+
+// reloadingTemplate parses its template again each time it is executed, so
+// that changes can be seen without restarting. It is only for development.
+type reloadingTemplate struct {
+	name   string
+	reload TemplateLoader
+}
+
+var templateErrorPage = template.Must(template.New("").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><title>Template error</title></head>
+<body>
+  <h1>Template error</h1>
+  <pre>{{ . }}</pre>
+</body>
+</html>
+`))
+
+func (t *reloadingTemplate) ExecuteTemplate(w io.Writer, name string, data interface{}) error {
+	templates, err := t.reload()
+
+	tmpl, ok := templates[t.name]
+	if !ok {
+		if err == nil {
+			err = fmt.Errorf("template %s is not loaded", t.name)
+		}
+
+		return renderTemplateError(w, err)
+	}
+
+	return tmpl.ExecuteTemplate(w, name, data)
+}
+
+// renderTemplateError shows why a template could not be parsed in place of
+// the page.
+func renderTemplateError(w io.Writer, err error) error {
+	if rw, ok := w.(http.ResponseWriter); ok {
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		rw.WriteHeader(http.StatusInternalServerError)
+	}
+
+	return templateErrorPage.Execute(w, err.Error())
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadingTemplate(t *testing.T) {
+	assert := assert.New(t)
+
+	count := 0
+	tmpl := &reloadingTemplate{
+		name: "users.gotmpl",
+		reload: func() (map[string]*template.Template, error) {
+			count += 1
+			return map[string]*template.Template{
+				"users.gotmpl": template.Must(template.New("").Parse(`{{ define "page" }}hello {{ . }}{{ end }}`)),
+			}, errors.New("another template is broken")
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.Nil(tmpl.ExecuteTemplate(&buf, "page", "world"))
+	assert.Nil(tmpl.ExecuteTemplate(&buf, "page", "again"))
+
+	assert.Equal(2, count)
+	assert.Equal("hello worldhello again", buf.String())
+}
+
+func TestReloadingTemplateParseError(t *testing.T) {
+	assert := assert.New(t)
+
+	tmpl := &reloadingTemplate{
+		name: "users.gotmpl",
+		reload: func() (map[string]*template.Template, error) {
+			return map[string]*template.Template{}, errors.New(`could not parse users.gotmpl: unexpected "<" in command`)
+		},
+	}
+
+	w := httptest.NewRecorder()
+	assert.Nil(tmpl.ExecuteTemplate(w, "page", nil))
+
+	assert.Equal(http.StatusInternalServerError, w.Code)
+	assert.Equal("text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(w.Body.String(), "could not parse users.gotmpl: unexpected &#34;&lt;&#34; in command")
+}
+
+func TestReloadingTemplateMissing(t *testing.T) {
+	tmpl := &reloadingTemplate{
+		name: "users.gotmpl",
+		reload: func() (map[string]*template.Template, error) {
+			return map[string]*template.Template{}, nil
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.Nil(t, tmpl.ExecuteTemplate(&buf, "page", nil))
+	assert.Contains(t, buf.String(), "template users.gotmpl is not loaded")
+}
+
+func TestTemplateLookupReload(t *testing.T) {
+	reload := func() (map[string]*template.Template, error) { return nil, nil }
+	lookup := &templateLookup{reload: reload}
+
+	tmpl := lookup.get("users.gotmpl")
+
+	assert.IsType(t, &reloadingTemplate{}, tmpl)
+	assert.Len(t, lookup.errs, 1)
+}
@@ -76,9 +76,11 @@ func removeTeamMember(client RemoveTeamMemberClient, tmpl Template) Handler {
 				}
 			}
 
+			before := team
 			team.Members = members
 
 			err = client.EditTeam(ctx, team)
+			recordAudit(r, "remove-team-member", fmt.Sprintf("team:%d", team.ID), before, team, err)
 
 			if _, ok := err.(sirius.ClientError); ok {
 				vars.Errors = sirius.ValidationErrors{
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+type ResendConfirmationClient interface {
+	ResendConfirmation(sirius.Context, int) error
+}
+
+type resendConfirmationVars struct {
+	Path  string
+	ID    int
+	Email string
+}
+
+func resendConfirmation(client ResendConfirmationClient, tmpl Template) Handler {
+	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		if !perm.HasPermission("v1-users", http.MethodPut) {
+			return StatusError(http.StatusForbidden)
+		}
+
+		if r.Method != http.MethodPost {
+			return StatusError(http.StatusMethodNotAllowed)
+		}
+
+		id, err := strconv.Atoi(r.PostFormValue("id"))
+		if err != nil {
+			return StatusError(http.StatusBadRequest)
+		}
+
+		err = client.ResendConfirmation(getContext(r), id)
+		recordAudit(r, "resend-confirmation", fmt.Sprintf("user:%d", id), nil, nil, err)
+		if err != nil {
+			return err
+		}
+
+		vars := resendConfirmationVars{
+			Path:  r.URL.Path,
+			ID:    id,
+			Email: r.PostFormValue("email"),
+		}
+
+		return tmpl.ExecuteTemplate(w, "page", vars)
+	}
+}
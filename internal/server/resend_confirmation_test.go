@@ -0,0 +1,114 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockResendConfirmationClient struct {
+	count   int
+	lastCtx sirius.Context
+	lastID  int
+	err     error
+}
+
+func (m *mockResendConfirmationClient) ResendConfirmation(ctx sirius.Context, id int) error {
+	m.count += 1
+	m.lastCtx = ctx
+	m.lastID = id
+
+	return m.err
+}
+
+func (m *mockResendConfirmationClient) requiredPermissions() sirius.PermissionSet {
+	return sirius.PermissionSet{"v1-users": sirius.PermissionGroup{Permissions: []string{"put"}}}
+}
+
+func TestPostResendConfirmation(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockResendConfirmationClient{}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/resend-confirmation", strings.NewReader("id=123&email=a@example.com"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := resendConfirmation(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(1, client.count)
+	assert.Equal(getContext(r), client.lastCtx)
+	assert.Equal(123, client.lastID)
+
+	assert.Equal(1, template.count)
+	assert.Equal("page", template.lastName)
+	assert.Equal(resendConfirmationVars{
+		Path:  "/resend-confirmation",
+		ID:    123,
+		Email: "a@example.com",
+	}, template.lastVars)
+}
+
+func TestPostResendConfirmationNoPermission(t *testing.T) {
+	assert := assert.New(t)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/resend-confirmation", nil)
+
+	err := resendConfirmation(nil, nil)(sirius.PermissionSet{}, w, r)
+	assert.Equal(StatusError(http.StatusForbidden), err)
+}
+
+func TestPostResendConfirmationBadID(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockResendConfirmationClient{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/resend-confirmation", strings.NewReader("id=hello"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := resendConfirmation(client, nil)(client.requiredPermissions(), w, r)
+	assert.Equal(StatusError(http.StatusBadRequest), err)
+	assert.Equal(0, client.count)
+}
+
+func TestPostResendConfirmationError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedError := errors.New("oops")
+
+	client := &mockResendConfirmationClient{}
+	client.err = expectedError
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/resend-confirmation", strings.NewReader("id=123&email=a@example.com"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := resendConfirmation(client, template)(client.requiredPermissions(), w, r)
+	assert.Equal(expectedError, err)
+
+	assert.Equal(1, client.count)
+	assert.Equal(0, template.count)
+}
+
+func TestResendConfirmationBadMethod(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockResendConfirmationClient{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/resend-confirmation", nil)
+
+	err := resendConfirmation(client, nil)(client.requiredPermissions(), w, r)
+	assert.Equal(StatusError(http.StatusMethodNotAllowed), err)
+	assert.Equal(0, client.count)
+}
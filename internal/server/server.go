@@ -6,12 +6,17 @@ import (
 	"fmt"
 	"html/template"
 	"io"
+	"io/fs"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/ministryofjustice/opg-go-common/securityheaders"
 	"github.com/ministryofjustice/opg-go-common/telemetry"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/audit"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/metrics"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/randomreview"
 	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
@@ -35,104 +40,248 @@ type Client interface {
 	ViewTeamClient
 	RandomReviewsClient
 	EditRandomReviewSettingsClient
+	ReviewRandomReviewProposalClient
 	FeedbackFormClient
+	ResendConfirmationClient
+	ImportUsersClient
+	ExportTeamsClient
+	ExportUsersClient
+	SuspendUsersClient
+	MoveTeamMembersClient
+	AuditClient
+	ViewUserClient
+	HealthCheckClient
 }
 
 type Template interface {
 	ExecuteTemplate(io.Writer, string, interface{}) error
 }
 
-func New(logger *slog.Logger, client Client, templates map[string]*template.Template, prefix, siriusPublicURL, webDir string) http.Handler {
-	wrap := errorHandler(client, templates["error.gotmpl"], prefix, siriusPublicURL)
+// Options holds the settings and dependencies of the service that are not
+// needed by every caller of New. Any left as the zero value are not used.
+type Options struct {
+	Prefix          string
+	SiriusPublicURL string
+	AuditSink       audit.Sink
+	ReviewStore     RandomReviewStore
+	Population      randomreview.Population
+	Metrics         *metrics.Metrics
+	WebFS           fs.FS
+
+	// ReloadTemplates, if not nil, is called to parse the templates again
+	// each time a page is rendered.
+	ReloadTemplates TemplateLoader
+}
+
+// New creates the handler for the service.
+func New(logger *slog.Logger, client Client, templates map[string]*template.Template, opts Options) http.Handler {
+	mux := routes(client, &templateLookup{templates: templates, reload: opts.ReloadTemplates}, opts)
+
+	middleware := telemetry.Middleware(logger)
+
+	return otelhttp.NewHandler(http.StripPrefix(opts.Prefix, securityheaders.Use(middleware(withAudit(client, opts.AuditSink)(withMetrics(opts.Metrics, time.Now)(mux))))), "user-management")
+}
+
+// limitRequestBody stops more than n bytes of a request body being read. It
+// must be outside errorHandler, as that parses the form to find the XSRF
+// token before the handler runs.
+func limitRequestBody(n int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, n)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CheckTemplates returns an error for each template used by a route that has
+// not been loaded, or that does not define "page".
+func CheckTemplates(templates map[string]*template.Template) error {
+	// the handlers are only built to find which templates they use, they are
+	// never served, so need no client or options
+	lookup := &templateLookup{templates: templates}
+	routes(nil, lookup, Options{})
+
+	return errors.Join(lookup.errs...)
+}
+
+func routes(client Client, templates *templateLookup, opts Options) *http.ServeMux {
+	prefix, reviewStore, population := opts.Prefix, opts.ReviewStore, opts.Population
+
+	wrap := errorHandler(client, templates.get("error.gotmpl"), prefix, opts.SiriusPublicURL)
+	wrapAPI := apiErrorHandler(client)
 
 	mux := http.NewServeMux()
 	mux.Handle("/", http.RedirectHandler(prefix+"/my-details", http.StatusFound))
 	mux.Handle("/health-check", healthCheck())
+	mux.Handle("/health-check/ready", healthCheckReady(client, templates.templates, opts.WebFS, siriusProbeTimeout))
 
 	mux.Handle("/users",
 		wrap(
-			listUsers(client, templates["users.gotmpl"])))
+			listUsers(client, templates.get("users.gotmpl"))))
+
+	mux.Handle("/users/",
+		wrap(
+			viewUser(client, templates.get("user.gotmpl"))))
+
+	mux.Handle("/users/import",
+		limitRequestBody(maxImportUsersRequestSize,
+			wrap(
+				importUsers(client, templates.get("import-users.gotmpl")))))
+
+	mux.Handle("/users/export.csv",
+		wrap(
+			exportUsers(client)))
+
+	mux.Handle("/users/suspend",
+		wrap(
+			suspendUsers(client, templates.get("suspend-users.gotmpl"))))
 
 	mux.Handle("/teams",
 		wrap(
-			listTeams(client, templates["teams.gotmpl"])))
+			listTeams(client, templates.get("teams.gotmpl"))))
 
 	mux.Handle("/teams/",
 		wrap(
-			viewTeam(client, templates["team.gotmpl"])))
+			viewTeam(client, templates.get("team.gotmpl"))))
+
+	mux.Handle("/teams/export.csv",
+		wrap(
+			exportTeams(client, exportFormatCSV)))
+
+	mux.Handle("/teams/export.json",
+		wrap(
+			exportTeams(client, exportFormatJSON)))
 
 	mux.Handle("/teams/add",
 		wrap(
-			addTeam(client, templates["team-add.gotmpl"])))
+			addTeam(client, templates.get("team-add.gotmpl"))))
 
 	mux.Handle("/teams/edit/",
 		wrap(
-			editTeam(client, templates["team-edit.gotmpl"])))
+			editTeam(client, templates.get("team-edit.gotmpl"))))
 
 	mux.Handle("/teams/delete/",
 		wrap(
-			deleteTeam(client, templates["team-delete.gotmpl"])))
+			deleteTeam(client, templates.get("team-delete.gotmpl"))))
 
 	mux.Handle("/teams/add-member/",
 		wrap(
-			addTeamMember(client, templates["team-add-member.gotmpl"])))
+			addTeamMember(client, templates.get("team-add-member.gotmpl"))))
 
 	mux.Handle("/teams/remove-member/",
 		wrap(
-			removeTeamMember(client, templates["team-remove-member.gotmpl"])))
+			removeTeamMember(client, templates.get("team-remove-member.gotmpl"))))
+
+	mux.Handle("/teams/move-members/",
+		wrap(
+			moveTeamMembers(client, templates.get("team-move-members.gotmpl"))))
 
 	mux.Handle("/my-details",
 		wrap(
-			myDetails(client, templates["my-details.gotmpl"])))
+			myDetails(client, templates.get("my-details.gotmpl"))))
 
 	mux.Handle("/my-details/edit",
 		wrap(
-			editMyDetails(client, templates["edit-my-details.gotmpl"])))
+			editMyDetails(client, templates.get("edit-my-details.gotmpl"))))
 
 	mux.Handle("/random-reviews",
 		wrap(
-			randomReviews(client, templates["random-reviews.gotmpl"])))
+			randomReviews(client, reviewStore, templates.get("random-reviews.gotmpl"))))
 
-	mux.Handle("/random-reviews/edit/lay-percentage",
+	mux.Handle("/random-reviews/history.csv",
 		wrap(
-			editRandomReviewSettings(client, templates["random-reviews-edit-lay-percentage.gotmpl"])))
+			exportRandomReviewHistory(reviewStore)))
 
-	mux.Handle("/random-reviews/edit/pa-percentage",
+	mux.Handle("/random-reviews/proposals/",
 		wrap(
-			editRandomReviewSettings(client, templates["random-reviews-edit-pa-percentage.gotmpl"])))
+			reviewRandomReviewProposal(client, reviewStore, population, templates.get("random-reviews-proposal.gotmpl"))))
 
-	mux.Handle("/random-reviews/edit/pro-percentage",
+	mux.Handle("/random-reviews/schedule",
 		wrap(
-			editRandomReviewSettings(client, templates["random-reviews-edit-pro-percentage.gotmpl"])))
+			scheduleRandomReview(client, reviewStore, templates.get("random-reviews-schedule.gotmpl"))))
 
-	mux.Handle("/random-reviews/edit/review-cycle",
+	mux.Handle("/random-reviews/edit",
 		wrap(
-			editRandomReviewSettings(client, templates["random-reviews-edit-review-cycle.gotmpl"])))
+			editRandomReviewSettings(client, reviewStore, population, templates.get("random-reviews-edit.gotmpl"))))
+
+	// the settings used to be changed on a page each, so keep links to them
+	// working
+	for path, field := range map[string]string{
+		"/random-reviews/edit/lay-percentage": "layPercentage",
+		"/random-reviews/edit/pa-percentage":  "paPercentage",
+		"/random-reviews/edit/pro-percentage": "proPercentage",
+		"/random-reviews/edit/review-cycle":   "reviewCycle",
+	} {
+		mux.Handle(path, http.RedirectHandler(prefix+"/random-reviews/edit#f-"+field, http.StatusMovedPermanently))
+	}
 
 	mux.Handle("/add-user",
 		wrap(
-			addUser(client, templates["add-user.gotmpl"])))
+			addUser(client, templates.get("add-user.gotmpl"))))
 
 	mux.Handle("/edit-user/",
 		wrap(
-			editUser(client, templates["edit-user.gotmpl"])))
+			editUser(client, templates.get("edit-user.gotmpl"))))
 
 	mux.Handle("/delete-user/",
 		wrap(
-			deleteUser(client, templates["delete-user.gotmpl"])))
+			deleteUser(client, templates.get("delete-user.gotmpl"))))
+
+	mux.Handle("/resend-confirmation",
+		wrap(
+			resendConfirmation(client, templates.get("resend-confirmation.gotmpl"))))
 
 	mux.Handle("/feedback",
 		wrap(
-			feedbackForm(client, templates["feedback.gotmpl"])))
+			feedbackForm(client, templates.get("feedback.gotmpl"))))
+
+	mux.Handle("/api/users",
+		wrapAPI(
+			apiListUsers(client)))
+
+	mux.Handle("/api/teams",
+		wrapAPI(
+			apiListTeams(client)))
+
+	mux.Handle("/api/teams/",
+		wrapAPI(
+			apiViewTeam(client)))
 
-	static := http.FileServer(http.Dir(webDir + "/static"))
+	mux.Handle("/api/random-reviews",
+		wrapAPI(
+			apiRandomReviews(client)))
+
+	mux.Handle("/api/my-details",
+		wrapAPI(
+			apiMyDetails(client)))
+
+	staticFS, _ := fs.Sub(opts.WebFS, "static")
+	static := http.FileServerFS(staticFS)
 	mux.Handle("/assets/", static)
 	mux.Handle("/javascript/", static)
 	mux.Handle("/stylesheets/", static)
 
-	middleware := telemetry.Middleware(logger)
+	return mux
+}
+
+type templateLookup struct {
+	templates map[string]*template.Template
+	reload    TemplateLoader
+	errs      []error
+}
+
+func (l *templateLookup) get(name string) Template {
+	tmpl, ok := l.templates[name]
+	if !ok || tmpl == nil {
+		l.errs = append(l.errs, fmt.Errorf("template %s is not loaded", name))
+	} else if tmpl.Lookup("page") == nil {
+		l.errs = append(l.errs, fmt.Errorf("template %s does not define page", name))
+	}
+
+	if l.reload != nil {
+		return &reloadingTemplate{name: name, reload: l.reload}
+	}
 
-	return otelhttp.NewHandler(http.StripPrefix(prefix, securityheaders.Use(middleware(mux))), "user-management")
+	return tmpl
 }
 
 type RedirectError string
@@ -178,6 +327,7 @@ func errorHandler(client ErrorHandlerClient, tmplError Template, prefix, siriusU
 
 			if err == nil {
 				err = next(myPermissions, w, r)
+				recordHandlerMetrics(r, err)
 			}
 
 			if err != nil {
@@ -208,10 +358,12 @@ func errorHandler(client ErrorHandlerClient, tmplError Template, prefix, siriusU
 					if status.Code() == http.StatusForbidden || status.Code() == http.StatusNotFound {
 						code = status.Code()
 					}
+				} else if errors.Is(err, sirius.ErrUnavailable) {
+					code = http.StatusServiceUnavailable
 				}
 
 				logger := telemetry.LoggerFromContext(r.Context())
-				if code == http.StatusInternalServerError {
+				if code == http.StatusInternalServerError || code == http.StatusServiceUnavailable {
 					logger.Error(err.Error())
 				}
 
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html/template"
 	"io"
 	"log/slog"
 	"net/http"
@@ -53,7 +54,28 @@ func contextWithLogger() (context.Context, *bytes.Buffer) {
 }
 
 func TestNew(t *testing.T) {
-	assert.Implements(t, (*http.Handler)(nil), New(nil, nil, nil, "", "", ""))
+	assert.Implements(t, (*http.Handler)(nil), New(nil, nil, nil, Options{}))
+}
+
+func TestOldRandomReviewEditRoutes(t *testing.T) {
+	mux := routes(nil, &templateLookup{}, Options{Prefix: "/prefix"})
+
+	for path, location := range map[string]string{
+		"/random-reviews/edit/lay-percentage": "/prefix/random-reviews/edit#f-layPercentage",
+		"/random-reviews/edit/pa-percentage":  "/prefix/random-reviews/edit#f-paPercentage",
+		"/random-reviews/edit/pro-percentage": "/prefix/random-reviews/edit#f-proPercentage",
+		"/random-reviews/edit/review-cycle":   "/prefix/random-reviews/edit#f-reviewCycle",
+	} {
+		t.Run(path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest("GET", path, nil)
+
+			mux.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusMovedPermanently, w.Code)
+			assert.Equal(t, location, w.Header().Get("Location"))
+		})
+	}
 }
 
 func TestErrorHandler(t *testing.T) {
@@ -223,6 +245,32 @@ func TestErrorHandlerStatusKnown(t *testing.T) {
 	}
 }
 
+func TestErrorHandlerSiriusUnavailable(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, logBuf := contextWithLogger()
+	client := &mockErrorHandlerClient{}
+	tmplError := &mockTemplate{}
+
+	wrap := errorHandler(client, tmplError, "/prefix", "http://sirius")
+	handler := wrap(func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		return fmt.Errorf("%w: connection refused", sirius.ErrUnavailable)
+	})
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequestWithContext(ctx, "GET", "/path", nil)
+
+	handler.ServeHTTP(w, r)
+
+	resp := w.Result()
+	assert.Equal(http.StatusServiceUnavailable, resp.StatusCode)
+
+	assert.Equal(1, tmplError.count)
+	assert.Equal(errorVars{SiriusURL: "http://sirius", Code: http.StatusServiceUnavailable, Error: "Sirius is unavailable: connection refused"}, tmplError.lastVars)
+
+	assert.Contains(logBuf.String(), "Sirius is unavailable")
+}
+
 func TestErrorHandlerTemplateError(t *testing.T) {
 	assert := assert.New(t)
 
@@ -328,3 +376,19 @@ func TestCancelledContext(t *testing.T) {
 	assert.Equal(499, resp.StatusCode)
 	assert.Equal("", logBuf.String())
 }
+
+func TestCheckTemplates(t *testing.T) {
+	page := template.Must(template.New("").Parse(`{{ define "page" }}{{ end }}`))
+
+	templates := map[string]*template.Template{
+		"users.gotmpl": template.Must(template.New("").Parse(`hello`)),
+	}
+
+	err := CheckTemplates(templates)
+	assert.ErrorContains(t, err, "template users.gotmpl does not define page")
+	assert.ErrorContains(t, err, "template error.gotmpl is not loaded")
+	assert.ErrorContains(t, err, "template teams.gotmpl is not loaded")
+
+	templates["users.gotmpl"] = page
+	assert.NotContains(t, CheckTemplates(templates).Error(), "template users.gotmpl")
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/ministryofjustice/opg-go-common/telemetry"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+type SuspendUsersClient interface {
+	User(sirius.Context, int) (sirius.AuthUser, error)
+	EditUser(sirius.Context, sirius.AuthUser) error
+}
+
+type suspendUsersVars struct {
+	Path      string
+	XSRFToken string
+	Search    string
+	Suspend   bool
+	Users     []sirius.AuthUser
+	Results   []suspendUserResult
+	Errors    sirius.ValidationErrors
+}
+
+type suspendUserResult struct {
+	User    sirius.AuthUser
+	Success bool
+	Errors  sirius.ValidationErrors
+}
+
+func suspendUsers(client SuspendUsersClient, tmpl Template) Handler {
+	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		if !perm.HasPermission("v1-users", http.MethodPut) {
+			return StatusError(http.StatusForbidden)
+		}
+
+		if r.Method != http.MethodPost {
+			return StatusError(http.StatusMethodNotAllowed)
+		}
+
+		if err := r.ParseForm(); err != nil {
+			return StatusError(http.StatusBadRequest)
+		}
+
+		ctx := getContext(r)
+
+		vars := suspendUsersVars{
+			Path:      r.URL.Path,
+			XSRFToken: ctx.XSRFToken,
+			Search:    r.PostFormValue("search"),
+		}
+
+		switch r.PostFormValue("action") {
+		case "suspend":
+			vars.Suspend = true
+		case "reactivate":
+			vars.Suspend = false
+		default:
+			return StatusError(http.StatusBadRequest)
+		}
+
+		if len(r.PostForm["selected[]"]) == 0 {
+			vars.Errors = sirius.ValidationErrors{
+				"#": {"": "Select at least one user"},
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			return tmpl.ExecuteTemplate(w, "page", vars)
+		}
+
+		for _, id := range r.PostForm["selected[]"] {
+			userID, err := strconv.Atoi(id)
+			if err != nil {
+				return StatusError(http.StatusBadRequest)
+			}
+
+			user, err := client.User(ctx, userID)
+			if err != nil {
+				return err
+			}
+
+			vars.Users = append(vars.Users, user)
+		}
+
+		if r.PostFormValue("confirm") != "" {
+			for _, user := range vars.Users {
+				before := user
+				user.Suspended = vars.Suspend
+				result := suspendUserResult{User: user}
+
+				err := client.EditUser(ctx, user)
+				recordAudit(r, "edit-user", fmt.Sprintf("user:%d", user.ID), before, user, err)
+
+				if verr, ok := err.(sirius.ValidationError); ok {
+					result.Errors = verr.Errors
+				} else if err != nil {
+					// carry on, so that the users already changed are still shown
+					telemetry.LoggerFromContext(r.Context()).Error("could not edit user", slog.Any("err", err.Error()), slog.Int("id", user.ID))
+					result.Errors = sirius.ValidationErrors{
+						"#": {"": "The user could not be changed, try again"},
+					}
+				} else {
+					result.Success = true
+				}
+
+				vars.Results = append(vars.Results, result)
+			}
+		}
+
+		return tmpl.ExecuteTemplate(w, "page", vars)
+	}
+}
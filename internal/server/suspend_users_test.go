@@ -0,0 +1,249 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSuspendUsersClient struct {
+	user struct {
+		count   int
+		lastIDs []int
+		data    map[int]sirius.AuthUser
+		err     error
+	}
+
+	editUser struct {
+		count     int
+		lastCtx   sirius.Context
+		users     []sirius.AuthUser
+		errByUser map[int]error
+	}
+}
+
+func (m *mockSuspendUsersClient) User(ctx sirius.Context, id int) (sirius.AuthUser, error) {
+	m.user.count += 1
+	m.user.lastIDs = append(m.user.lastIDs, id)
+
+	return m.user.data[id], m.user.err
+}
+
+func (m *mockSuspendUsersClient) EditUser(ctx sirius.Context, user sirius.AuthUser) error {
+	m.editUser.count += 1
+	m.editUser.lastCtx = ctx
+	m.editUser.users = append(m.editUser.users, user)
+
+	return m.editUser.errByUser[user.ID]
+}
+
+func (m *mockSuspendUsersClient) requiredPermissions() sirius.PermissionSet {
+	return sirius.PermissionSet{"v1-users": sirius.PermissionGroup{Permissions: []string{"put"}}}
+}
+
+func newMockSuspendUsersClient() *mockSuspendUsersClient {
+	client := &mockSuspendUsersClient{}
+	client.user.data = map[int]sirius.AuthUser{
+		12: {ID: 12, Firstname: "Anne", Surname: "Able", Email: "a@example.com", Organisation: "OPG User", Roles: []string{"Finance"}},
+		45: {ID: 45, Firstname: "Bob", Surname: "Bell", Email: "b@example.com", Organisation: "COP User"},
+	}
+
+	return client
+}
+
+func TestPostSuspendUsers(t *testing.T) {
+	assert := assert.New(t)
+
+	client := newMockSuspendUsersClient()
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/users/suspend", strings.NewReader("action=suspend&search=able&selected[]=12&selected[]=45"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := suspendUsers(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal([]int{12, 45}, client.user.lastIDs)
+	assert.Equal(0, client.editUser.count)
+
+	assert.Equal(1, template.count)
+	assert.Equal("page", template.lastName)
+	assert.Equal(suspendUsersVars{
+		Path:    "/users/suspend",
+		Search:  "able",
+		Suspend: true,
+		Users:   []sirius.AuthUser{client.user.data[12], client.user.data[45]},
+	}, template.lastVars)
+}
+
+func TestPostSuspendUsersConfirm(t *testing.T) {
+	assert := assert.New(t)
+
+	client := newMockSuspendUsersClient()
+	client.editUser.errByUser = map[int]error{
+		45: sirius.ValidationError{Errors: sirius.ValidationErrors{"suspended": {"notAllowed": "Cannot suspend"}}},
+	}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/users/suspend", strings.NewReader("action=suspend&confirm=confirm&selected[]=12&selected[]=45"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := suspendUsers(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(2, client.editUser.count)
+	assert.Equal(getContext(r), client.editUser.lastCtx)
+
+	suspendedAnne := client.user.data[12]
+	suspendedAnne.Suspended = true
+	suspendedBob := client.user.data[45]
+	suspendedBob.Suspended = true
+
+	assert.Equal([]sirius.AuthUser{suspendedAnne, suspendedBob}, client.editUser.users)
+
+	assert.Equal([]suspendUserResult{
+		{User: suspendedAnne, Success: true},
+		{User: suspendedBob, Errors: sirius.ValidationErrors{"suspended": {"notAllowed": "Cannot suspend"}}},
+	}, template.lastVars.(suspendUsersVars).Results)
+}
+
+func TestPostSuspendUsersConfirmEditError(t *testing.T) {
+	assert := assert.New(t)
+
+	client := newMockSuspendUsersClient()
+	client.user.data[78] = sirius.AuthUser{ID: 78, Firstname: "Cat", Surname: "Cole", Email: "c@example.com"}
+	client.editUser.errByUser = map[int]error{
+		45: errors.New("oops"),
+	}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/users/suspend", strings.NewReader("action=suspend&confirm=confirm&selected[]=12&selected[]=45&selected[]=78"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := suspendUsers(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(3, client.editUser.count)
+	assert.Equal(1, template.count)
+
+	suspendedAnne := client.user.data[12]
+	suspendedAnne.Suspended = true
+	suspendedBob := client.user.data[45]
+	suspendedBob.Suspended = true
+	suspendedCat := client.user.data[78]
+	suspendedCat.Suspended = true
+
+	assert.Equal([]suspendUserResult{
+		{User: suspendedAnne, Success: true},
+		{User: suspendedBob, Errors: sirius.ValidationErrors{"#": {"": "The user could not be changed, try again"}}},
+		{User: suspendedCat, Success: true},
+	}, template.lastVars.(suspendUsersVars).Results)
+}
+
+func TestPostReactivateUsersConfirm(t *testing.T) {
+	assert := assert.New(t)
+
+	client := newMockSuspendUsersClient()
+	anne := client.user.data[12]
+	anne.Suspended = true
+	client.user.data[12] = anne
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/users/suspend", strings.NewReader("action=reactivate&confirm=confirm&selected[]=12"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := suspendUsers(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(1, client.editUser.count)
+	assert.False(client.editUser.users[0].Suspended)
+	assert.False(template.lastVars.(suspendUsersVars).Suspend)
+}
+
+func TestPostSuspendUsersNoneSelected(t *testing.T) {
+	assert := assert.New(t)
+
+	client := newMockSuspendUsersClient()
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/users/suspend", strings.NewReader("action=suspend&search=able"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := suspendUsers(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(http.StatusBadRequest, w.Result().StatusCode)
+	assert.Equal(0, client.user.count)
+	assert.Equal(suspendUsersVars{
+		Path:    "/users/suspend",
+		Search:  "able",
+		Suspend: true,
+		Errors: sirius.ValidationErrors{
+			"#": {"": "Select at least one user"},
+		},
+	}, template.lastVars)
+}
+
+func TestPostSuspendUsersBadAction(t *testing.T) {
+	assert := assert.New(t)
+
+	client := newMockSuspendUsersClient()
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/users/suspend", strings.NewReader("action=delete&selected[]=12"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := suspendUsers(client, nil)(client.requiredPermissions(), w, r)
+	assert.Equal(StatusError(http.StatusBadRequest), err)
+}
+
+func TestPostSuspendUsersUserError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedError := errors.New("oops")
+
+	client := newMockSuspendUsersClient()
+	client.user.err = expectedError
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/users/suspend", strings.NewReader("action=suspend&confirm=confirm&selected[]=12"))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	err := suspendUsers(client, template)(client.requiredPermissions(), w, r)
+	assert.Equal(expectedError, err)
+	assert.Equal(0, client.editUser.count)
+	assert.Equal(0, template.count)
+}
+
+func TestPostSuspendUsersNoPermission(t *testing.T) {
+	assert := assert.New(t)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/users/suspend", nil)
+
+	err := suspendUsers(nil, nil)(sirius.PermissionSet{}, w, r)
+	assert.Equal(StatusError(http.StatusForbidden), err)
+}
+
+func TestSuspendUsersBadMethod(t *testing.T) {
+	assert := assert.New(t)
+
+	client := newMockSuspendUsersClient()
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/users/suspend", nil)
+
+	err := suspendUsers(client, nil)(client.requiredPermissions(), w, r)
+	assert.Equal(StatusError(http.StatusMethodNotAllowed), err)
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+)
+
+type ViewUserClient interface {
+	User(sirius.Context, int) (sirius.AuthUser, error)
+	Teams(sirius.Context) ([]sirius.Team, error)
+}
+
+type viewUserVars struct {
+	Path      string
+	User      sirius.AuthUser
+	Teams     []sirius.Team
+	CanEdit   bool
+	CanDelete bool
+}
+
+func viewUser(client ViewUserClient, tmpl Template) Handler {
+	return func(perm sirius.PermissionSet, w http.ResponseWriter, r *http.Request) error {
+		if !perm.HasPermission("v1-users", http.MethodPut) {
+			return StatusError(http.StatusForbidden)
+		}
+
+		if r.Method != http.MethodGet {
+			return StatusError(http.StatusMethodNotAllowed)
+		}
+
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/users/"))
+		if err != nil {
+			return StatusError(http.StatusNotFound)
+		}
+
+		ctx := getContext(r)
+
+		user, err := client.User(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		teams, err := client.Teams(ctx)
+		if err != nil {
+			return err
+		}
+
+		vars := viewUserVars{
+			Path:      r.URL.Path,
+			User:      user,
+			CanEdit:   perm.HasPermission("v1-users", http.MethodPut),
+			CanDelete: perm.HasPermission("v1-users", http.MethodDelete),
+		}
+
+		for _, team := range teams {
+			for _, member := range team.Members {
+				if member.ID == user.ID {
+					vars.Teams = append(vars.Teams, team)
+					break
+				}
+			}
+		}
+
+		return tmpl.ExecuteTemplate(w, "page", vars)
+	}
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockViewUserClient struct {
+	user struct {
+		count   int
+		lastCtx sirius.Context
+		lastID  int
+		data    sirius.AuthUser
+		err     error
+	}
+
+	teams struct {
+		count int
+		data  []sirius.Team
+		err   error
+	}
+}
+
+func (m *mockViewUserClient) User(ctx sirius.Context, id int) (sirius.AuthUser, error) {
+	m.user.count += 1
+	m.user.lastCtx = ctx
+	m.user.lastID = id
+
+	return m.user.data, m.user.err
+}
+
+func (m *mockViewUserClient) Teams(ctx sirius.Context) ([]sirius.Team, error) {
+	m.teams.count += 1
+
+	return m.teams.data, m.teams.err
+}
+
+func (m *mockViewUserClient) requiredPermissions() sirius.PermissionSet {
+	return sirius.PermissionSet{"v1-users": sirius.PermissionGroup{Permissions: []string{"put"}}}
+}
+
+func TestViewUser(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockViewUserClient{}
+	client.user.data = sirius.AuthUser{ID: 123, Firstname: "Anne", Roles: []string{"Finance"}}
+	client.teams.data = []sirius.Team{
+		{ID: 1, DisplayName: "Cool Team", Members: []sirius.TeamMember{{ID: 5}, {ID: 123}}},
+		{ID: 2, DisplayName: "Other Team", Members: []sirius.TeamMember{{ID: 5}}},
+		{ID: 3, DisplayName: "Cover Team", Members: []sirius.TeamMember{{ID: 123}}},
+	}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/users/123", nil)
+
+	err := viewUser(client, template)(client.requiredPermissions(), w, r)
+	assert.Nil(err)
+
+	assert.Equal(1, client.user.count)
+	assert.Equal(getContext(r), client.user.lastCtx)
+	assert.Equal(123, client.user.lastID)
+	assert.Equal(1, client.teams.count)
+
+	assert.Equal(1, template.count)
+	assert.Equal("page", template.lastName)
+	assert.Equal(viewUserVars{
+		Path: "/users/123",
+		User: client.user.data,
+		Teams: []sirius.Team{
+			client.teams.data[0],
+			client.teams.data[2],
+		},
+		CanEdit: true,
+	}, template.lastVars)
+}
+
+func TestViewUserCanDelete(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockViewUserClient{}
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/users/123", nil)
+
+	perm := sirius.PermissionSet{"v1-users": sirius.PermissionGroup{Permissions: []string{"put", "delete"}}}
+
+	err := viewUser(client, template)(perm, w, r)
+	assert.Nil(err)
+
+	assert.True(template.lastVars.(viewUserVars).CanDelete)
+}
+
+func TestViewUserNoPermission(t *testing.T) {
+	assert := assert.New(t)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/users/123", nil)
+
+	err := viewUser(nil, nil)(sirius.PermissionSet{}, w, r)
+	assert.Equal(StatusError(http.StatusForbidden), err)
+}
+
+func TestViewUserBadPath(t *testing.T) {
+	for name, path := range map[string]string{
+		"empty":       "/users/",
+		"non-numeric": "/users/hello",
+		"suffixed":    "/users/123/no",
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			client := &mockViewUserClient{}
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest("GET", path, nil)
+
+			err := viewUser(client, nil)(client.requiredPermissions(), w, r)
+			assert.Equal(StatusError(http.StatusNotFound), err)
+		})
+	}
+}
+
+func TestViewUserBadMethod(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &mockViewUserClient{}
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/users/123", nil)
+
+	err := viewUser(client, nil)(client.requiredPermissions(), w, r)
+	assert.Equal(StatusError(http.StatusMethodNotAllowed), err)
+}
+
+func TestViewUserUserError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedErr := errors.New("oops")
+	client := &mockViewUserClient{}
+	client.user.err = expectedErr
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/users/123", nil)
+
+	err := viewUser(client, template)(client.requiredPermissions(), w, r)
+	assert.Equal(expectedErr, err)
+
+	assert.Equal(0, client.teams.count)
+	assert.Equal(0, template.count)
+}
+
+func TestViewUserTeamsError(t *testing.T) {
+	assert := assert.New(t)
+
+	expectedErr := errors.New("oops")
+	client := &mockViewUserClient{}
+	client.teams.err = expectedErr
+	template := &mockTemplate{}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/users/123", nil)
+
+	err := viewUser(client, template)(client.requiredPermissions(), w, r)
+	assert.Equal(expectedErr, err)
+
+	assert.Equal(0, template.count)
+}
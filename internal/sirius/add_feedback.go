@@ -30,7 +30,7 @@ func (c *Client) AddFeedback(ctx Context, form model.FeedbackForm) error {
 		return err
 	}
 
-	req, err := c.newRequest(ctx, http.MethodPost, "/supervision-api/v1/feedback/supervision", &body)
+	req, err := c.newRequest(ctx, "AddFeedback", http.MethodPost, "/supervision-api/v1/feedback/supervision", &body)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,193 @@
+package sirius
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachingClient wraps a Client to avoid repeatedly fetching reference data and
+// the permissions of the current user from Sirius.
+//
+// Roles and team types are shared between all users and cached for
+// referenceTTL. Permissions are cached for permissionsTTL against the session
+// cookies they were requested with, and are all dropped after any call that
+// changes data in Sirius, since that could change somebody's permissions.
+type CachingClient struct {
+	*Client
+
+	referenceTTL   time.Duration
+	permissionsTTL time.Duration
+	now            func() time.Time
+
+	mu          sync.Mutex
+	roles       cacheEntry[[]string]
+	teamTypes   cacheEntry[[]RefDataTeamType]
+	permissions map[string]cacheEntry[PermissionSet]
+}
+
+type cacheEntry[T any] struct {
+	value   T
+	expires time.Time
+}
+
+func (e cacheEntry[T]) valid(now time.Time) bool {
+	return now.Before(e.expires)
+}
+
+func NewCachingClient(client *Client, referenceTTL, permissionsTTL time.Duration) *CachingClient {
+	return &CachingClient{
+		Client:         client,
+		referenceTTL:   referenceTTL,
+		permissionsTTL: permissionsTTL,
+		now:            time.Now,
+		permissions:    map[string]cacheEntry[PermissionSet]{},
+	}
+}
+
+func (c *CachingClient) Roles(ctx Context) ([]string, error) {
+	c.mu.Lock()
+	entry := c.roles
+	c.mu.Unlock()
+
+	if entry.valid(c.now()) {
+		return slices.Clone(entry.value), nil
+	}
+
+	roles, err := c.Client.Roles(ctx)
+	if err != nil {
+		return roles, err
+	}
+
+	c.mu.Lock()
+	c.roles = cacheEntry[[]string]{value: slices.Clone(roles), expires: c.now().Add(c.referenceTTL)}
+	c.mu.Unlock()
+
+	return roles, nil
+}
+
+func (c *CachingClient) TeamTypes(ctx Context) ([]RefDataTeamType, error) {
+	c.mu.Lock()
+	entry := c.teamTypes
+	c.mu.Unlock()
+
+	if entry.valid(c.now()) {
+		return slices.Clone(entry.value), nil
+	}
+
+	teamTypes, err := c.Client.TeamTypes(ctx)
+	if err != nil {
+		return teamTypes, err
+	}
+
+	c.mu.Lock()
+	c.teamTypes = cacheEntry[[]RefDataTeamType]{value: slices.Clone(teamTypes), expires: c.now().Add(c.referenceTTL)}
+	c.mu.Unlock()
+
+	return teamTypes, nil
+}
+
+func (c *CachingClient) MyPermissions(ctx Context) (PermissionSet, error) {
+	key := sessionKey(ctx)
+	if key == "" {
+		return c.Client.MyPermissions(ctx)
+	}
+
+	now := c.now()
+
+	c.mu.Lock()
+	entry, ok := c.permissions[key]
+	c.mu.Unlock()
+
+	if ok && entry.valid(now) {
+		return entry.value, nil
+	}
+
+	permissions, err := c.Client.MyPermissions(ctx)
+	if err != nil {
+		return permissions, err
+	}
+
+	c.mu.Lock()
+	for k, e := range c.permissions {
+		if !e.valid(now) {
+			delete(c.permissions, k)
+		}
+	}
+	c.permissions[key] = cacheEntry[PermissionSet]{value: permissions, expires: now.Add(c.permissionsTTL)}
+	c.mu.Unlock()
+
+	return permissions, nil
+}
+
+func (c *CachingClient) AddTeam(ctx Context, name, teamType, phone, email string) (int, error) {
+	defer c.invalidatePermissions()
+	return c.Client.AddTeam(ctx, name, teamType, phone, email)
+}
+
+func (c *CachingClient) AddUser(ctx Context, email, firstName, lastName, organisation string, roles []string) error {
+	defer c.invalidatePermissions()
+	return c.Client.AddUser(ctx, email, firstName, lastName, organisation, roles)
+}
+
+func (c *CachingClient) DeleteTeam(ctx Context, teamID int) error {
+	defer c.invalidatePermissions()
+	return c.Client.DeleteTeam(ctx, teamID)
+}
+
+func (c *CachingClient) DeleteUser(ctx Context, userID int) error {
+	defer c.invalidatePermissions()
+	return c.Client.DeleteUser(ctx, userID)
+}
+
+func (c *CachingClient) EditMyDetails(ctx Context, id int, phoneNumber string) error {
+	defer c.invalidatePermissions()
+	return c.Client.EditMyDetails(ctx, id, phoneNumber)
+}
+
+func (c *CachingClient) EditRandomReviewSettings(ctx Context, reviewSettings EditRandomReview) error {
+	defer c.invalidatePermissions()
+	return c.Client.EditRandomReviewSettings(ctx, reviewSettings)
+}
+
+func (c *CachingClient) EditTeam(ctx Context, team Team) error {
+	defer c.invalidatePermissions()
+	return c.Client.EditTeam(ctx, team)
+}
+
+func (c *CachingClient) EditUser(ctx Context, user AuthUser) error {
+	defer c.invalidatePermissions()
+	return c.Client.EditUser(ctx, user)
+}
+
+func (c *CachingClient) invalidatePermissions() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.permissions)
+}
+
+// sessionKey identifies the session that a request was made for, without
+// keeping the cookie values themselves. The XSRF token is ignored as it does
+// not affect who the user is.
+func sessionKey(ctx Context) string {
+	var parts []string
+	for _, cookie := range ctx.Cookies {
+		if cookie.Name != "XSRF-TOKEN" {
+			parts = append(parts, cookie.Name+"="+cookie.Value)
+		}
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+
+	sort.Strings(parts)
+	sum := sha256.Sum256([]byte(strings.Join(parts, ";")))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,198 @@
+package sirius
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	hits map[string]int
+}
+
+func newCountingServer() *countingServer {
+	s := &countingServer{hits: map[string]int{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.hits[r.Method+" "+r.URL.Path]++
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/v1/roles":
+			_, _ = w.Write([]byte(`["Finance", "OPG User"]`))
+		case "/api/v1/reference-data":
+			_, _ = w.Write([]byte(`{"teamType":[{"handle":"ALLOCATIONS","label":"Allocations"}]}`))
+		case "/api/v1/permissions":
+			_, _ = w.Write([]byte(`{"v1-users":{"permissions":["PUT"]}}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+
+	return s
+}
+
+func (s *countingServer) count(key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.hits[key]
+}
+
+func newTestCachingClient(url string) (*CachingClient, *time.Time) {
+	now := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	client, _ := NewClient(http.DefaultClient, url)
+	c := NewCachingClient(client, time.Minute, 10*time.Second)
+	c.now = func() time.Time { return now }
+
+	return c, &now
+}
+
+func TestCachingClientReferenceData(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newCountingServer()
+	defer s.Close()
+
+	client, now := newTestCachingClient(s.URL)
+	ctx := Context{Context: context.Background()}
+
+	for i := 0; i < 3; i++ {
+		roles, err := client.Roles(ctx)
+		assert.Nil(err)
+		assert.Equal([]string{"Finance"}, roles)
+
+		teamTypes, err := client.TeamTypes(ctx)
+		assert.Nil(err)
+		assert.Equal([]RefDataTeamType{{Handle: "ALLOCATIONS", Label: "Allocations"}}, teamTypes)
+	}
+
+	assert.Equal(1, s.count("GET /api/v1/roles"))
+	assert.Equal(1, s.count("GET /api/v1/reference-data"))
+
+	*now = now.Add(time.Minute)
+
+	_, _ = client.Roles(ctx)
+	_, _ = client.TeamTypes(ctx)
+
+	assert.Equal(2, s.count("GET /api/v1/roles"))
+	assert.Equal(2, s.count("GET /api/v1/reference-data"))
+}
+
+func TestCachingClientReferenceDataIsCopied(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newCountingServer()
+	defer s.Close()
+
+	client, _ := newTestCachingClient(s.URL)
+	ctx := Context{Context: context.Background()}
+
+	roles, _ := client.Roles(ctx)
+	roles[0] = "Changed"
+
+	roles, _ = client.Roles(ctx)
+	assert.Equal([]string{"Finance"}, roles)
+}
+
+func TestCachingClientDoesNotCacheErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	s := teapotServer()
+	defer s.Close()
+
+	client, _ := newTestCachingClient(s.URL)
+	ctx := Context{Context: context.Background()}
+
+	_, err := client.Roles(ctx)
+	assert.IsType(StatusError{}, err)
+
+	_, err = client.MyPermissions(Context{Context: context.Background(), Cookies: []*http.Cookie{{Name: "sirius", Value: "a"}}})
+	assert.IsType(StatusError{}, err)
+
+	assert.Empty(client.permissions)
+	assert.False(client.roles.valid(client.now()))
+}
+
+func TestCachingClientPermissionsPerSession(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newCountingServer()
+	defer s.Close()
+
+	client, now := newTestCachingClient(s.URL)
+
+	alice := Context{Context: context.Background(), Cookies: []*http.Cookie{{Name: "sirius", Value: "alice"}, {Name: "XSRF-TOKEN", Value: "1"}}}
+	aliceAgain := Context{Context: context.Background(), Cookies: []*http.Cookie{{Name: "XSRF-TOKEN", Value: "2"}, {Name: "sirius", Value: "alice"}}}
+	bob := Context{Context: context.Background(), Cookies: []*http.Cookie{{Name: "sirius", Value: "bob"}}}
+
+	permissions, err := client.MyPermissions(alice)
+	assert.Nil(err)
+	assert.True(permissions.HasPermission("v1-users", http.MethodPut))
+
+	_, _ = client.MyPermissions(aliceAgain)
+	assert.Equal(1, s.count("GET /api/v1/permissions"))
+
+	_, _ = client.MyPermissions(bob)
+	assert.Equal(2, s.count("GET /api/v1/permissions"))
+
+	*now = now.Add(10 * time.Second)
+
+	_, _ = client.MyPermissions(alice)
+	assert.Equal(3, s.count("GET /api/v1/permissions"))
+}
+
+func TestCachingClientPermissionsWithoutSession(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newCountingServer()
+	defer s.Close()
+
+	client, _ := newTestCachingClient(s.URL)
+	ctx := Context{Context: context.Background()}
+
+	_, _ = client.MyPermissions(ctx)
+	_, _ = client.MyPermissions(ctx)
+
+	assert.Equal(2, s.count("GET /api/v1/permissions"))
+}
+
+func TestCachingClientInvalidatesPermissions(t *testing.T) {
+	ctx := Context{Context: context.Background(), Cookies: []*http.Cookie{{Name: "sirius", Value: "alice"}}}
+
+	for name, fn := range map[string]func(*CachingClient){
+		"AddTeam":                  func(c *CachingClient) { _, _ = c.AddTeam(ctx, "", "", "", "") },
+		"AddUser":                  func(c *CachingClient) { _ = c.AddUser(ctx, "", "", "", "", nil) },
+		"DeleteTeam":               func(c *CachingClient) { _ = c.DeleteTeam(ctx, 1) },
+		"DeleteUser":               func(c *CachingClient) { _ = c.DeleteUser(ctx, 1) },
+		"EditMyDetails":            func(c *CachingClient) { _ = c.EditMyDetails(ctx, 1, "") },
+		"EditRandomReviewSettings": func(c *CachingClient) { _ = c.EditRandomReviewSettings(ctx, EditRandomReview{}) },
+		"EditTeam":                 func(c *CachingClient) { _ = c.EditTeam(ctx, Team{ID: 1}) },
+		"EditUser":                 func(c *CachingClient) { _ = c.EditUser(ctx, AuthUser{ID: 1}) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			s := newCountingServer()
+			defer s.Close()
+
+			client, _ := newTestCachingClient(s.URL)
+
+			_, _ = client.MyPermissions(ctx)
+			fn(client)
+			_, _ = client.MyPermissions(ctx)
+
+			assert.Equal(2, s.count("GET /api/v1/permissions"))
+		})
+	}
+}
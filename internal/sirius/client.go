@@ -74,8 +74,24 @@ type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-func (c *Client) newRequest(ctx Context, method, path string, body io.Reader) (*http.Request, error) {
-	req, err := http.NewRequestWithContext(ctx.Context, method, c.baseURL+path, body)
+type operationKey struct{}
+
+// Operation returns the name of the Client method that made the request, so
+// that calls to Sirius can be told apart without parsing their URLs.
+func Operation(req *http.Request) string {
+	op, _ := req.Context().Value(operationKey{}).(string)
+	return op
+}
+
+// newRequest creates a request to Sirius. The operation should be the name of
+// the Client method making it, and is used to label metrics.
+func (c *Client) newRequest(ctx Context, operation, method, path string, body io.Reader) (*http.Request, error) {
+	reqCtx := ctx.Context
+	if reqCtx != nil {
+		reqCtx = context.WithValue(reqCtx, operationKey{}, operation)
+	}
+
+	req, err := http.NewRequestWithContext(reqCtx, method, c.baseURL+path, body)
 	if err != nil {
 		return nil, err
 	}
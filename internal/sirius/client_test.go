@@ -1,6 +1,7 @@
 package sirius
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -67,3 +68,23 @@ func TestStatusError(t *testing.T) {
 	assert.Equal(t, "unexpected response from Sirius", err.Title())
 	assert.Equal(t, err, err.Data())
 }
+
+type operationRecorder struct {
+	operations []string
+}
+
+func (r *operationRecorder) Do(req *http.Request) (*http.Response, error) {
+	r.operations = append(r.operations, Operation(req))
+
+	return &http.Response{StatusCode: http.StatusTeapot, Body: http.NoBody, Request: req}, nil
+}
+
+func TestOperation(t *testing.T) {
+	recorder := &operationRecorder{}
+	client, _ := NewClient(recorder, "http://localhost")
+
+	_, _ = client.Team(Context{Context: context.Background()}, 1)
+	_, _ = client.Roles(Context{Context: context.Background()})
+
+	assert.Equal(t, []string{"Team", "Roles"}, recorder.operations)
+}
@@ -32,7 +32,7 @@ func (c *Client) EditUser(ctx Context, user AuthUser) error {
 
 	requestURL := fmt.Sprintf("/api/v1/users/%d", user.ID)
 
-	req, err := c.newRequest(ctx, http.MethodPut, requestURL, &body)
+	req, err := c.newRequest(ctx, "EditUser", http.MethodPut, requestURL, &body)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,26 @@
+package sirius
+
+import (
+	"net/http"
+)
+
+// Ping checks that Sirius can be reached at the configured URL. It does not
+// send the user's cookies, so an unauthorised response counts as success.
+func (c *Client) Ping(ctx Context) error {
+	req, err := c.newRequest(Context{Context: ctx.Context}, "Ping", http.MethodGet, "/api/v1/users/current", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // no need to check error when closing body
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusUnauthorized {
+		return newStatusError(resp)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package sirius
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPing(t *testing.T) {
+	for name, code := range map[string]int{
+		"OK":           http.StatusOK,
+		"Unauthorized": http.StatusUnauthorized,
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/v1/users/current", r.URL.Path)
+				assert.Empty(t, r.Cookies())
+				w.WriteHeader(code)
+			}))
+			defer s.Close()
+
+			client, _ := NewClient(http.DefaultClient, s.URL)
+
+			err := client.Ping(Context{
+				Context: context.Background(),
+				Cookies: []*http.Cookie{{Name: "sirius", Value: "abc"}},
+			})
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestPingStatusError(t *testing.T) {
+	s := teapotServer()
+	defer s.Close()
+
+	client, _ := NewClient(http.DefaultClient, s.URL)
+
+	err := client.Ping(Context{Context: context.Background()})
+	assert.Equal(t, StatusError{
+		Code:   http.StatusTeapot,
+		URL:    s.URL + "/api/v1/users/current",
+		Method: http.MethodGet,
+	}, err)
+}
+
+func TestPingUnreachable(t *testing.T) {
+	s := teapotServer()
+	s.Close()
+
+	client, _ := NewClient(http.DefaultClient, s.URL)
+
+	err := client.Ping(Context{Context: context.Background()})
+	assert.NotNil(t, err)
+}
@@ -15,7 +15,7 @@ type RandomReviews struct {
 func (c *Client) RandomReviews(ctx Context) (RandomReviews, error) {
 	var data RandomReviews
 
-	req, err := c.newRequest(ctx, http.MethodGet, "/supervision-api/v1/random-review-settings", nil)
+	req, err := c.newRequest(ctx, "RandomReviews", http.MethodGet, "/supervision-api/v1/random-review-settings", nil)
 	if err != nil {
 		return data, err
 	}
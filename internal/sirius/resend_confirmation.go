@@ -0,0 +1,29 @@
+package sirius
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func (c *Client) ResendConfirmation(ctx Context, id int) error {
+	req, err := c.newRequest(ctx, "ResendConfirmation", http.MethodPost, fmt.Sprintf("/api/v1/users/%d/resend-confirmation", id), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // no need to check error when closing body
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return ErrUnauthorized
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return newStatusError(resp)
+	}
+
+	return nil
+}
@@ -0,0 +1,236 @@
+package sirius
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable is returned when Sirius cannot be reached, either because
+// repeated attempts have failed or responded with a 502, 503 or 504, or
+// because the circuit breaker is open.
+var ErrUnavailable = errors.New("Sirius is unavailable")
+
+// RetryPolicy controls how GET requests are retried when Sirius cannot be
+// reached or responds with a 502, 503 or 504.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+	// BaseDelay is doubled for each attempt and a random delay up to that
+	// value is waited before retrying.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts.
+	MaxDelay time.Duration
+}
+
+// BreakerPolicy controls when requests to Sirius stop being made.
+type BreakerPolicy struct {
+	// FailureThreshold is the number of consecutive failed requests after
+	// which the breaker opens. Zero disables the breaker.
+	FailureThreshold int
+	// Cooldown is how long the breaker stays open before a single request is
+	// allowed through to check whether Sirius has recovered.
+	Cooldown time.Duration
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// ResilientHTTPClient wraps a HTTPClient to retry idempotent requests with
+// jittered backoff, and to fail fast while Sirius is down.
+type ResilientHTTPClient struct {
+	http    HTTPClient
+	retry   RetryPolicy
+	breaker BreakerPolicy
+
+	now    func() time.Time
+	sleep  func(context.Context, time.Duration) error
+	jitter func(time.Duration) time.Duration
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+func NewResilientHTTPClient(httpClient HTTPClient, retry RetryPolicy, breaker BreakerPolicy) *ResilientHTTPClient {
+	if retry.MaxAttempts < 1 {
+		retry.MaxAttempts = 1
+	}
+
+	return &ResilientHTTPClient{
+		http:    httpClient,
+		retry:   retry,
+		breaker: breaker,
+		now:     time.Now,
+		sleep:   sleepContext,
+		jitter: func(d time.Duration) time.Duration {
+			if d <= 0 {
+				return 0
+			}
+			return time.Duration(rand.Int64N(int64(d) + 1)) //nolint:gosec // jitter does not need a secure source
+		},
+	}
+}
+
+func (c *ResilientHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	attempts := 1
+	if isIdempotent(req.Method) {
+		attempts = c.retry.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		if !c.allow() {
+			return nil, ErrUnavailable
+		}
+
+		resp, err := c.http.Do(req)
+
+		switch {
+		case req.Context().Err() != nil:
+			c.release()
+		case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+			c.record(true)
+		default:
+			c.record(false)
+		}
+
+		if !shouldRetry(resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		if attempt >= attempts {
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+			}
+
+			// writes are not retried, so leave their response for the caller
+			// to report
+			if !isIdempotent(req.Method) {
+				return resp, nil
+			}
+
+			discard(resp)
+			return nil, fmt.Errorf("%w: responded with status %d", ErrUnavailable, resp.StatusCode)
+		}
+
+		if resp != nil {
+			discard(resp)
+		}
+
+		if err := c.sleep(req.Context(), c.backoff(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *ResilientHTTPClient) backoff(attempt int) time.Duration {
+	delay := c.retry.BaseDelay << (attempt - 1)
+	if c.retry.MaxDelay > 0 && (delay > c.retry.MaxDelay || delay <= 0) {
+		delay = c.retry.MaxDelay
+	}
+
+	return c.jitter(delay)
+}
+
+// allow reports whether a request may be made, moving an open breaker to
+// half-open once the cooldown has passed.
+func (c *ResilientHTTPClient) allow() bool {
+	if c.breaker.FailureThreshold <= 0 {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case breakerOpen:
+		if c.now().Sub(c.openedAt) < c.breaker.Cooldown {
+			return false
+		}
+		c.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+func (c *ResilientHTTPClient) record(failed bool) {
+	if c.breaker.FailureThreshold <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !failed {
+		c.state = breakerClosed
+		c.failures = 0
+		return
+	}
+
+	c.failures++
+	if c.state == breakerHalfOpen || c.failures >= c.breaker.FailureThreshold {
+		c.state = breakerOpen
+		c.openedAt = c.now()
+	}
+}
+
+// release lets another request check Sirius when a half-open check could not
+// complete because the request was cancelled.
+func (c *ResilientHTTPClient) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == breakerHalfOpen {
+		c.state = breakerOpen
+	}
+}
+
+func isIdempotent(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// discard reads and closes a response body so that its connection can be
+// reused.
+func discard(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
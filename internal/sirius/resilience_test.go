@@ -0,0 +1,205 @@
+package sirius
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type flakyServer struct {
+	*httptest.Server
+	hits     atomic.Int32
+	failures int32
+	status   int
+}
+
+// newFlakyServer responds with status to the first failures requests and
+// then returns an empty list.
+func newFlakyServer(failures int32, status int) *flakyServer {
+	s := &flakyServer{failures: failures, status: status}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.hits.Add(1) <= s.failures {
+			w.WriteHeader(s.status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("[]"))
+	}))
+
+	return s
+}
+
+func newTestResilientClient(httpClient HTTPClient, retry RetryPolicy, breaker BreakerPolicy) (*ResilientHTTPClient, *[]time.Duration) {
+	var delays []time.Duration
+
+	c := NewResilientHTTPClient(httpClient, retry, breaker)
+	c.jitter = func(d time.Duration) time.Duration { return d }
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return ctx.Err()
+	}
+
+	return c, &delays
+}
+
+func TestResilientHTTPClientRetriesGet(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newFlakyServer(2, http.StatusServiceUnavailable)
+	defer s.Close()
+
+	httpClient, delays := newTestResilientClient(http.DefaultClient, RetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond}, BreakerPolicy{})
+	client, _ := NewClient(httpClient, s.URL)
+
+	roles, err := client.Roles(Context{Context: context.Background()})
+	assert.Nil(err)
+	assert.Empty(roles)
+
+	assert.Equal(int32(3), s.hits.Load())
+	assert.Equal([]time.Duration{10 * time.Millisecond, 20 * time.Millisecond}, *delays)
+}
+
+func TestResilientHTTPClientGivesUp(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newFlakyServer(5, http.StatusServiceUnavailable)
+	defer s.Close()
+
+	httpClient, _ := newTestResilientClient(http.DefaultClient, RetryPolicy{MaxAttempts: 3}, BreakerPolicy{})
+	client, _ := NewClient(httpClient, s.URL)
+
+	_, err := client.Teams(Context{Context: context.Background()})
+	assert.ErrorIs(err, ErrUnavailable)
+	assert.EqualError(err, "Sirius is unavailable: responded with status 503")
+	assert.Equal(int32(3), s.hits.Load())
+}
+
+func TestResilientHTTPClientDoesNotRetryOtherStatuses(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newFlakyServer(5, http.StatusInternalServerError)
+	defer s.Close()
+
+	httpClient, _ := newTestResilientClient(http.DefaultClient, RetryPolicy{MaxAttempts: 3}, BreakerPolicy{})
+	client, _ := NewClient(httpClient, s.URL)
+
+	_, err := client.Teams(Context{Context: context.Background()})
+	assert.IsType(StatusError{}, err)
+	assert.Equal(int32(1), s.hits.Load())
+}
+
+func TestResilientHTTPClientDoesNotRetryWrites(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newFlakyServer(5, http.StatusServiceUnavailable)
+	defer s.Close()
+
+	httpClient, _ := newTestResilientClient(http.DefaultClient, RetryPolicy{MaxAttempts: 3}, BreakerPolicy{})
+	client, _ := NewClient(httpClient, s.URL)
+
+	err := client.DeleteUser(Context{Context: context.Background()}, 123)
+	assert.IsType(StatusError{}, err)
+	assert.Equal(int32(1), s.hits.Load())
+}
+
+func TestResilientHTTPClientUnreachable(t *testing.T) {
+	assert := assert.New(t)
+
+	s := httptest.NewServer(http.NotFoundHandler())
+	s.Close()
+
+	httpClient, _ := newTestResilientClient(http.DefaultClient, RetryPolicy{MaxAttempts: 2}, BreakerPolicy{})
+	client, _ := NewClient(httpClient, s.URL)
+
+	_, err := client.TeamTypes(Context{Context: context.Background()})
+	assert.ErrorIs(err, ErrUnavailable)
+}
+
+func TestResilientHTTPClientCancelled(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newFlakyServer(5, http.StatusServiceUnavailable)
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	httpClient := NewResilientHTTPClient(http.DefaultClient, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour}, BreakerPolicy{})
+	httpClient.sleep = func(context.Context, time.Duration) error {
+		cancel()
+		return context.Canceled
+	}
+	client, _ := NewClient(httpClient, s.URL)
+
+	_, err := client.Teams(Context{Context: ctx})
+	assert.ErrorIs(err, context.Canceled)
+	assert.Equal(int32(1), s.hits.Load())
+}
+
+func TestResilientHTTPClientBreaker(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newFlakyServer(2, http.StatusInternalServerError)
+	defer s.Close()
+
+	now := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	httpClient, _ := newTestResilientClient(http.DefaultClient, RetryPolicy{MaxAttempts: 1}, BreakerPolicy{FailureThreshold: 2, Cooldown: time.Minute})
+	httpClient.now = func() time.Time { return now }
+	client, _ := NewClient(httpClient, s.URL)
+
+	ctx := Context{Context: context.Background()}
+
+	_, err := client.Teams(ctx)
+	assert.IsType(StatusError{}, err)
+	_, err = client.Teams(ctx)
+	assert.IsType(StatusError{}, err)
+
+	_, err = client.Teams(ctx)
+	assert.Equal(ErrUnavailable, err)
+	assert.Equal(int32(2), s.hits.Load())
+
+	now = now.Add(time.Minute)
+
+	_, err = client.Teams(ctx)
+	assert.Nil(err)
+	assert.Equal(int32(3), s.hits.Load())
+
+	_, err = client.Teams(ctx)
+	assert.Nil(err)
+}
+
+func TestResilientHTTPClientBreakerReopens(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	httpClient, _ := newTestResilientClient(&mockHTTPClient{err: errors.New("connection refused")}, RetryPolicy{MaxAttempts: 1}, BreakerPolicy{FailureThreshold: 1, Cooldown: time.Minute})
+	httpClient.now = func() time.Time { return now }
+
+	req, _ := http.NewRequest(http.MethodGet, "http://sirius/api/v1/teams", nil)
+
+	_, err := httpClient.Do(req)
+	assert.ErrorIs(err, ErrUnavailable)
+
+	now = now.Add(time.Minute)
+	_, err = httpClient.Do(req)
+	assert.ErrorIs(err, ErrUnavailable)
+	assert.NotEqual(ErrUnavailable, err)
+
+	_, err = httpClient.Do(req)
+	assert.Equal(ErrUnavailable, err)
+}
+
+type mockHTTPClient struct {
+	err error
+}
+
+func (m *mockHTTPClient) Do(*http.Request) (*http.Response, error) {
+	return nil, m.err
+}
@@ -36,7 +36,34 @@ type User struct {
 	DisplayName string `json:"displayName"`
 	Email       string `json:"email"`
 	Status      UserStatus
-	Team        string `json:"team"`
+	Team        string     `json:"team"`
+	Teams       []UserTeam `json:"teams"`
+}
+
+type UserTeam struct {
+	ID          int    `json:"id"`
+	DisplayName string `json:"displayName"`
+}
+
+// TeamNames lists the names of all of the teams the user is in.
+func (u User) TeamNames() []string {
+	names := make([]string, len(u.Teams))
+	for i, team := range u.Teams {
+		names[i] = team.DisplayName
+	}
+
+	return names
+}
+
+// InTeam reports whether the user is a member of the team with the given ID.
+func (u User) InTeam(id int) bool {
+	for _, team := range u.Teams {
+		if team.ID == id {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (c *Client) SearchUsers(ctx Context, search string) ([]User, error) {
@@ -44,7 +71,7 @@ func (c *Client) SearchUsers(ctx Context, search string) ([]User, error) {
 		return nil, ClientError("Search term must be at least three characters")
 	}
 
-	req, err := c.newRequest(ctx, http.MethodGet, "/api/v1/search/users?includeSuspended=1&query="+url.QueryEscape(search), nil)
+	req, err := c.newRequest(ctx, "SearchUsers", http.MethodGet, "/api/v1/search/users?includeSuspended=1&query="+url.QueryEscape(search), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -92,6 +119,13 @@ func (c *Client) SearchUsers(ctx Context, search string) ([]User, error) {
 			Team:        teamName,
 		}
 
+		for _, t := range u.Teams {
+			user.Teams = append(user.Teams, UserTeam{
+				ID:          t.ID,
+				DisplayName: t.DisplayName,
+			})
+		}
+
 		if u.Suspended {
 			user.Status = "Suspended"
 		}
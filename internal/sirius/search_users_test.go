@@ -47,6 +47,7 @@ func TestSearchUsers(t *testing.T) {
 							"email":       matchers.String("anton.mccoy@opgtest.com"),
 							"suspended":   matchers.Like(false),
 							"teams": matchers.EachLike(map[string]interface{}{
+								"id":          matchers.Like(12),
 								"displayName": matchers.Like("my friendly team"),
 							}, 1),
 						}, 1),
@@ -60,6 +61,9 @@ func TestSearchUsers(t *testing.T) {
 					Email:       "anton.mccoy@opgtest.com",
 					Status:      "Active",
 					Team:        "my friendly team",
+					Teams: []UserTeam{
+						{ID: 12, DisplayName: "my friendly team"},
+					},
 				},
 			},
 		},
@@ -160,3 +164,17 @@ func TestUserStatus(t *testing.T) {
 	assert.Equal(t, "", UserStatus("string").TagColour())
 	assert.Equal(t, "govuk-tag--grey", UserStatus("Suspended").TagColour())
 }
+
+func TestUserTeams(t *testing.T) {
+	user := User{
+		Teams: []UserTeam{
+			{ID: 12, DisplayName: "Cool Team"},
+			{ID: 14, DisplayName: "Cover Team"},
+		},
+	}
+
+	assert.Equal(t, []string{"Cool Team", "Cover Team"}, user.TeamNames())
+	assert.True(t, user.InTeam(14))
+	assert.False(t, user.InTeam(13))
+	assert.Equal(t, []string{}, User{}.TeamNames())
+}
@@ -38,7 +38,7 @@ type Team struct {
 }
 
 func (c *Client) Teams(ctx Context) ([]Team, error) {
-	req, err := c.newRequest(ctx, http.MethodGet, "/api/v1/teams", nil)
+	req, err := c.newRequest(ctx, "Teams", http.MethodGet, "/api/v1/teams", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -70,10 +70,13 @@ func (c *Client) Teams(ctx Context) ([]Team, error) {
 			DisplayName: t.DisplayName,
 			Type:        "",
 			TypeLabel:   "LPA",
+			Email:       t.Email,
+			PhoneNumber: t.PhoneNumber,
 		}
 
 		for _, m := range t.Members {
 			teams[i].Members = append(teams[i].Members, TeamMember{
+				ID:          m.ID,
 				DisplayName: m.DisplayName,
 				Email:       m.Email,
 			})
@@ -42,7 +42,10 @@ func TestTeamsIgnoredPact(t *testing.T) {
 						Body: matchers.EachLike(map[string]interface{}{
 							"id":          matchers.Like(123),
 							"displayName": matchers.Like("Cool Team"),
+							"email":       matchers.Like("cool@opgtest.com"),
+							"phoneNumber": matchers.Like("0123456789"),
 							"members": matchers.EachLike(map[string]interface{}{
+								"id":          matchers.Like(123),
 								"displayName": matchers.Like("John"),
 								"email":       matchers.Like("john@opgtest.com"),
 							}, 1),
@@ -59,12 +62,15 @@ func TestTeamsIgnoredPact(t *testing.T) {
 					DisplayName: "Cool Team",
 					Members: []TeamMember{
 						{
+							ID:          123,
 							DisplayName: "John",
 							Email:       "john@opgtest.com",
 						},
 					},
-					Type:      "ALLOCATIONS",
-					TypeLabel: "Supervision — Allocations",
+					Type:        "ALLOCATIONS",
+					TypeLabel:   "Supervision — Allocations",
+					Email:       "cool@opgtest.com",
+					PhoneNumber: "0123456789",
 				},
 			},
 		},
@@ -87,6 +93,7 @@ func TestTeamsIgnoredPact(t *testing.T) {
 							"id":          matchers.Like(123),
 							"displayName": matchers.Like("Cool Team"),
 							"members": matchers.EachLike(map[string]interface{}{
+								"id":          matchers.Like(123),
 								"displayName": matchers.Like("John"),
 								"email":       matchers.Like("john@opgtest.com"),
 							}, 1),
@@ -99,6 +106,7 @@ func TestTeamsIgnoredPact(t *testing.T) {
 					DisplayName: "Cool Team",
 					Members: []TeamMember{
 						{
+							ID:          123,
 							DisplayName: "John",
 							Email:       "john@opgtest.com",
 						},
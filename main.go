@@ -2,23 +2,37 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"html/template"
+	"io/fs"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
-	"path/filepath"
+	"path"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/ministryofjustice/opg-go-common/env"
 	"github.com/ministryofjustice/opg-go-common/telemetry"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/audit"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/metrics"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/randomreview"
 	"github.com/ministryofjustice/opg-sirius-user-management/internal/server"
 	"github.com/ministryofjustice/opg-sirius-user-management/internal/sirius"
+	"github.com/ministryofjustice/opg-sirius-user-management/web"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
+const (
+	referenceDataTTL = 5 * time.Minute
+	permissionsTTL   = 30 * time.Second
+)
+
 func main() {
 	ctx := context.Background()
 	logger := telemetry.NewLogger("opg-sirius-user-management")
@@ -31,41 +45,73 @@ func main() {
 
 func run(ctx context.Context, logger *slog.Logger) error {
 	port := getEnv("PORT", "8080")
+	metricsPort := getEnv("METRICS_PORT", "9090")
 	webDir := getEnv("WEB_DIR", "web")
 	siriusURL := getEnv("SIRIUS_URL", "http://localhost:9001")
 	siriusPublicURL := getEnv("SIRIUS_PUBLIC_URL", "")
 	prefix := getEnv("PREFIX", "")
+	auditLogFile := getEnv("AUDIT_LOG_FILE", "")
+	randomReviewStoreFile := getEnv("RANDOM_REVIEW_STORE_FILE", "")
+	schedulerCookie := getEnv("SIRIUS_SCHEDULER_COOKIE", "")
 	exportTraces := env.Get("TRACING_ENABLED", "0") == "1"
+	devMode := env.Get("DEV_MODE", "0") == "1"
 
-	layouts, _ := template.
-		New("").
-		Funcs(map[string]interface{}{
-			"join": func(sep string, items []string) string {
-				return strings.Join(items, sep)
-			},
-			"contains": func(xs []string, needle string) bool {
-				for _, x := range xs {
-					if x == needle {
-						return true
-					}
-				}
+	var configErrs []error
 
-				return false
-			},
-			"prefix": func(s string) string {
-				return prefix + s
-			},
-			"sirius": func(s string) string {
-				return siriusPublicURL + s
-			},
-		}).
-		ParseGlob(webDir + "/template/layout/*.gotmpl")
-
-	files, _ := filepath.Glob(webDir + "/template/*.gotmpl")
-	tmpls := map[string]*template.Template{}
+	if err := validateConfig(siriusURL, siriusPublicURL, prefix, randomReviewStoreFile); err != nil {
+		configErrs = append(configErrs, err)
+	}
 
-	for _, file := range files {
-		tmpls[filepath.Base(file)] = template.Must(template.Must(layouts.Clone()).ParseFiles(file))
+	retryPolicy, breakerPolicy, err := siriusPolicies()
+	if err != nil {
+		configErrs = append(configErrs, err)
+	}
+
+	schedulerInterval, err := time.ParseDuration(getEnv("RANDOM_REVIEW_SCHEDULER_INTERVAL", "1m"))
+	if err == nil && schedulerInterval <= 0 {
+		err = errors.New("must be positive")
+	}
+	if err != nil {
+		configErrs = append(configErrs, fmt.Errorf("invalid RANDOM_REVIEW_SCHEDULER_INTERVAL: %w", err))
+	}
+
+	population, err := deputyPopulation()
+	if err != nil {
+		configErrs = append(configErrs, err)
+	}
+
+	schedulerAuth, err := siriusSessionAuth(schedulerCookie)
+	if err != nil {
+		configErrs = append(configErrs, fmt.Errorf("invalid SIRIUS_SCHEDULER_COOKIE: %w", err))
+	}
+
+	webFS := web.FS
+	if webFS == nil || os.Getenv("WEB_DIR") != "" || devMode {
+		webFS = os.DirFS(webDir)
+	}
+
+	var reloadTemplates server.TemplateLoader
+	if devMode {
+		reloadTemplates = func() (map[string]*template.Template, error) {
+			return loadTemplates(webFS, templateFuncs(prefix, siriusPublicURL))
+		}
+	}
+
+	tmpls, err := loadTemplates(webFS, templateFuncs(prefix, siriusPublicURL))
+	if err == nil {
+		err = server.CheckTemplates(tmpls)
+	}
+	if err != nil {
+		if devMode {
+			// errors will be shown when the affected pages are loaded
+			logger.Warn("template errors", slog.Any("err", err.Error()))
+		} else {
+			configErrs = append(configErrs, err)
+		}
+	}
+
+	if len(configErrs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(configErrs...))
 	}
 
 	shutdown, err := telemetry.StartTracerProvider(ctx, logger, exportTraces)
@@ -77,14 +123,57 @@ func run(ctx context.Context, logger *slog.Logger) error {
 	httpClient := http.DefaultClient
 	httpClient.Transport = otelhttp.NewTransport(httpClient.Transport)
 
-	client, err := sirius.NewClient(httpClient, siriusURL)
+	appMetrics := metrics.New()
+
+	// metrics are recorded inside the retries, so that each attempt is counted
+	// and calls refused by the breaker are not
+	client, err := sirius.NewClient(sirius.NewResilientHTTPClient(metrics.NewHTTPClient(httpClient, appMetrics), retryPolicy, breakerPolicy), siriusURL)
 	if err != nil {
 		return err
 	}
 
+	var auditSink audit.Sink = audit.NewLoggerSink(logger)
+	if auditLogFile != "" {
+		fileSink, err := audit.NewFileSink(auditLogFile)
+		if err != nil {
+			return err
+		}
+		defer fileSink.Close() //nolint:errcheck // no need to check error when closing file
+
+		auditSink = fileSink
+	}
+
+	reviewStore, err := randomreview.NewStore(randomReviewStoreFile)
+	if err != nil {
+		return fmt.Errorf("could not open random review store: %w", err)
+	}
+
+	cachingClient := sirius.NewCachingClient(client, referenceDataTTL, permissionsTTL)
+
+	schedulerCtx, stopScheduler := context.WithCancel(ctx)
+	defer stopScheduler()
+
+	if schedulerAuth != nil {
+		scheduler := randomreview.NewScheduler(logger, cachingClient, reviewStore, schedulerAuth, schedulerInterval)
+		go scheduler.Run(schedulerCtx)
+	} else {
+		logger.Warn("SIRIUS_SCHEDULER_COOKIE is not set, approved scheduled random review changes will not be made")
+	}
+
+	handler := server.New(logger, cachingClient, tmpls, server.Options{
+		Prefix:          prefix,
+		SiriusPublicURL: siriusPublicURL,
+		AuditSink:       auditSink,
+		ReviewStore:     reviewStore,
+		Population:      population,
+		Metrics:         appMetrics,
+		WebFS:           webFS,
+		ReloadTemplates: reloadTemplates,
+	})
+
 	server := &http.Server{
 		Addr:              ":" + port,
-		Handler:           server.New(logger, client, tmpls, prefix, siriusPublicURL, webDir),
+		Handler:           handler,
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 
@@ -95,6 +184,23 @@ func run(ctx context.Context, logger *slog.Logger) error {
 		}
 	}()
 
+	// metrics are kept off the public port, so they are not reachable through
+	// the proxy that serves the service under PREFIX
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", appMetrics.Handler())
+
+	metricsServer := &http.Server{
+		Addr:              ":" + metricsPort,
+		Handler:           metricsMux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("metrics listen and serve error", slog.Any("err", err.Error()))
+		}
+	}()
+
 	logger.Info("Running at :" + port)
 
 	c := make(chan os.Signal, 1)
@@ -103,12 +209,202 @@ func run(ctx context.Context, logger *slog.Logger) error {
 	sig := <-c
 	logger.Info("signal received: ", "sig", sig)
 
+	stopScheduler()
+
 	tc, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	_ = metricsServer.Shutdown(tc)
+
 	return server.Shutdown(tc)
 }
 
+func templateFuncs(prefix, siriusPublicURL string) template.FuncMap {
+	return template.FuncMap{
+		"join": func(sep string, items []string) string {
+			return strings.Join(items, sep)
+		},
+		"contains": func(xs []string, needle string) bool {
+			for _, x := range xs {
+				if x == needle {
+					return true
+				}
+			}
+
+			return false
+		},
+		"prefix": func(s string) string {
+			return prefix + s
+		},
+		"sirius": func(s string) string {
+			return siriusPublicURL + s
+		},
+	}
+}
+
+func loadTemplates(webFS fs.FS, funcs template.FuncMap) (map[string]*template.Template, error) {
+	layouts, err := template.New("").Funcs(funcs).ParseFS(webFS, "template/layout/*.gotmpl")
+	if err != nil {
+		return nil, fmt.Errorf("could not parse layouts: %w", err)
+	}
+
+	files, err := fs.Glob(webFS, "template/*.gotmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	tmpls := map[string]*template.Template{}
+	var errs []error
+
+	for _, file := range files {
+		tmpl, err := layouts.Clone()
+		if err == nil {
+			tmpl, err = tmpl.ParseFS(webFS, file)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not parse %s: %w", path.Base(file), err))
+			continue
+		}
+
+		tmpls[path.Base(file)] = tmpl
+	}
+
+	return tmpls, errors.Join(errs...)
+}
+
+func validateConfig(siriusURL, siriusPublicURL, prefix, randomReviewStoreFile string) error {
+	var errs []error
+
+	if err := validateBaseURL(siriusURL); err != nil {
+		errs = append(errs, fmt.Errorf("invalid SIRIUS_URL: %w", err))
+	}
+
+	if siriusPublicURL != "" {
+		if err := validateBaseURL(siriusPublicURL); err != nil {
+			errs = append(errs, fmt.Errorf("invalid SIRIUS_PUBLIC_URL: %w", err))
+		}
+	}
+
+	if prefix != "" && (!strings.HasPrefix(prefix, "/") || strings.HasSuffix(prefix, "/") || strings.ContainsAny(prefix, "?#")) {
+		errs = append(errs, fmt.Errorf("invalid PREFIX: must start with / and not end with /"))
+	}
+
+	// proposals awaiting approval and the change history would otherwise be
+	// lost on every deploy
+	if randomReviewStoreFile == "" {
+		errs = append(errs, errors.New("RANDOM_REVIEW_STORE_FILE must be set"))
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateBaseURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%q must be an http or https URL", s)
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("%q must include a host", s)
+	}
+
+	if u.RawQuery != "" || u.Fragment != "" || strings.HasSuffix(u.Path, "/") {
+		return fmt.Errorf("%q must not end with /, or include a query or fragment", s)
+	}
+
+	return nil
+}
+
+func siriusPolicies() (sirius.RetryPolicy, sirius.BreakerPolicy, error) {
+	var (
+		retry   sirius.RetryPolicy
+		breaker sirius.BreakerPolicy
+		err     error
+	)
+
+	if retry.MaxAttempts, err = strconv.Atoi(getEnv("SIRIUS_RETRY_ATTEMPTS", "3")); err != nil {
+		return retry, breaker, fmt.Errorf("invalid SIRIUS_RETRY_ATTEMPTS: %w", err)
+	}
+
+	if retry.BaseDelay, err = time.ParseDuration(getEnv("SIRIUS_RETRY_DELAY", "100ms")); err != nil {
+		return retry, breaker, fmt.Errorf("invalid SIRIUS_RETRY_DELAY: %w", err)
+	}
+	retry.MaxDelay = 2 * time.Second
+
+	if breaker.FailureThreshold, err = strconv.Atoi(getEnv("SIRIUS_BREAKER_THRESHOLD", "5")); err != nil {
+		return retry, breaker, fmt.Errorf("invalid SIRIUS_BREAKER_THRESHOLD: %w", err)
+	}
+
+	if breaker.Cooldown, err = time.ParseDuration(getEnv("SIRIUS_BREAKER_COOLDOWN", "30s")); err != nil {
+		return retry, breaker, fmt.Errorf("invalid SIRIUS_BREAKER_COOLDOWN: %w", err)
+	}
+
+	return retry, breaker, nil
+}
+
+// deputyPopulation reads the number of deputies of each type, used to estimate
+// how many reviews the random review settings will lead to.
+func deputyPopulation() (randomreview.Population, error) {
+	var (
+		population randomreview.Population
+		err        error
+	)
+
+	counts := []struct {
+		name  string
+		count *int
+	}{
+		{"LAY_DEPUTY_COUNT", &population.Lay},
+		{"PA_DEPUTY_COUNT", &population.PA},
+		{"PRO_DEPUTY_COUNT", &population.Pro},
+	}
+
+	for _, c := range counts {
+		if *c.count, err = strconv.Atoi(getEnv(c.name, "0")); err == nil && *c.count < 0 {
+			err = errors.New("must not be negative")
+		}
+		if err != nil {
+			return population, fmt.Errorf("invalid %s: %w", c.name, err)
+		}
+	}
+
+	return population, nil
+}
+
+// siriusSessionAuth gives the Sirius session for work done outside of a
+// request, from a Cookie header that includes an XSRF-TOKEN. It returns nil if
+// no cookies are given.
+func siriusSessionAuth(header string) (func(context.Context) sirius.Context, error) {
+	if header == "" {
+		return nil, nil
+	}
+
+	cookies, err := http.ParseCookie(header)
+	if err != nil {
+		return nil, err
+	}
+
+	token := ""
+	for _, cookie := range cookies {
+		if cookie.Name == "XSRF-TOKEN" {
+			token, _ = url.QueryUnescape(cookie.Value)
+		}
+	}
+
+	if token == "" {
+		return nil, errors.New("must include an XSRF-TOKEN cookie")
+	}
+
+	return func(ctx context.Context) sirius.Context {
+		return sirius.Context{Context: ctx, Cookies: cookies, XSRFToken: token}
+	}, nil
+}
+
 func getEnv(key, def string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
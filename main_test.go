@@ -1 +1,129 @@
 package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/randomreview"
+	"github.com/ministryofjustice/opg-sirius-user-management/internal/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadTemplates(t *testing.T) {
+	tmpls, err := loadTemplates(os.DirFS("web"), templateFuncs("/prefix", "http://sirius"))
+	assert.Nil(t, err)
+	assert.Contains(t, tmpls, "users.gotmpl")
+
+	assert.Nil(t, server.CheckTemplates(tmpls))
+}
+
+func TestLoadTemplatesErrors(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "template", "layout"), 0o755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "template", "layout", "page.gotmpl"), []byte(`{{ define "page" }}{{ end }}`), 0o600))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "template", "good.gotmpl"), []byte(`{{ template "page" . }}`), 0o600))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "template", "bad.gotmpl"), []byte(`{{ if }}`), 0o600))
+
+	tmpls, err := loadTemplates(os.DirFS(dir), templateFuncs("", ""))
+	assert.ErrorContains(t, err, "could not parse bad.gotmpl")
+	assert.Contains(t, tmpls, "good.gotmpl")
+	assert.NotContains(t, tmpls, "bad.gotmpl")
+}
+
+func TestLoadTemplatesMissingLayouts(t *testing.T) {
+	_, err := loadTemplates(os.DirFS(t.TempDir()), templateFuncs("", ""))
+	assert.ErrorContains(t, err, "could not parse layouts")
+}
+
+func TestValidateConfig(t *testing.T) {
+	assert.Nil(t, validateConfig("http://localhost:9001", "", "", "/data/random-reviews.json"))
+	assert.Nil(t, validateConfig("https://sirius.example.com", "https://sirius.example.com", "/users-admin", "/data/random-reviews.json"))
+
+	for name, tc := range map[string]struct {
+		siriusURL, siriusPublicURL, prefix string
+		randomReviewStoreFile              string
+		expected                           string
+	}{
+		"no scheme": {
+			siriusURL: "localhost:9001",
+			expected:  "invalid SIRIUS_URL",
+		},
+		"empty sirius url": {
+			siriusURL: "",
+			expected:  "invalid SIRIUS_URL",
+		},
+		"trailing slash": {
+			siriusURL:       "http://localhost:9001",
+			siriusPublicURL: "http://localhost:8080/",
+			expected:        "invalid SIRIUS_PUBLIC_URL",
+		},
+		"prefix without slash": {
+			siriusURL: "http://localhost:9001",
+			prefix:    "users",
+			expected:  "invalid PREFIX",
+		},
+		"prefix with trailing slash": {
+			siriusURL: "http://localhost:9001",
+			prefix:    "/users/",
+			expected:  "invalid PREFIX",
+		},
+		"no random review store": {
+			siriusURL: "http://localhost:9001",
+			expected:  "RANDOM_REVIEW_STORE_FILE must be set",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorContains(t, validateConfig(tc.siriusURL, tc.siriusPublicURL, tc.prefix, tc.randomReviewStoreFile), tc.expected)
+		})
+	}
+}
+
+func TestValidateConfigAggregatesErrors(t *testing.T) {
+	err := validateConfig("nope", "ftp://sirius", "nope", "")
+
+	assert.ErrorContains(t, err, "invalid SIRIUS_URL")
+	assert.ErrorContains(t, err, "invalid SIRIUS_PUBLIC_URL")
+	assert.ErrorContains(t, err, "invalid PREFIX")
+	assert.ErrorContains(t, err, "RANDOM_REVIEW_STORE_FILE must be set")
+}
+
+func TestSiriusSessionAuth(t *testing.T) {
+	assert := assert.New(t)
+
+	auth, err := siriusSessionAuth("")
+	assert.Nil(err)
+	assert.Nil(auth)
+
+	_, err = siriusSessionAuth("sirius=abc")
+	assert.NotNil(err)
+
+	auth, err = siriusSessionAuth("sirius=abc; XSRF-TOKEN=a%2Bb")
+	assert.Nil(err)
+
+	ctx := auth(context.Background())
+	assert.Equal("a+b", ctx.XSRFToken)
+	assert.Equal([]*http.Cookie{{Name: "sirius", Value: "abc"}, {Name: "XSRF-TOKEN", Value: "a%2Bb"}}, ctx.Cookies)
+}
+
+func TestDeputyPopulation(t *testing.T) {
+	assert := assert.New(t)
+
+	population, err := deputyPopulation()
+	assert.Nil(err)
+	assert.False(population.Known())
+
+	t.Setenv("LAY_DEPUTY_COUNT", "12000")
+	t.Setenv("PRO_DEPUTY_COUNT", "450")
+
+	population, err = deputyPopulation()
+	assert.Nil(err)
+	assert.Equal(randomreview.Population{Lay: 12000, Pro: 450}, population)
+
+	t.Setenv("PA_DEPUTY_COUNT", "-1")
+
+	_, err = deputyPopulation()
+	assert.EqualError(err, "invalid PA_DEPUTY_COUNT: must not be negative")
+}
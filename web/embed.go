@@ -0,0 +1,17 @@
+//go:build embed
+
+// Package web provides the templates and static assets when they have been
+// compiled in with the "embed" build tag.
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed template static
+var files embed.FS
+
+// FS contains the template and static directories. The assets must have been
+// built into web/static before compiling.
+var FS fs.FS = files
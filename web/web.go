@@ -0,0 +1,11 @@
+//go:build !embed
+
+// Package web provides the templates and static assets when they have been
+// compiled in with the "embed" build tag.
+package web
+
+import "io/fs"
+
+// FS is nil as the binary was built without the "embed" tag, so files are
+// read from WEB_DIR instead.
+var FS fs.FS